@@ -0,0 +1,182 @@
+package phygo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// One benchmark op is one UpdatePhysics(1/60) call, so ns/op and allocs/op
+// are per step. pairs/step and contacts/step are summed over its sub-steps.
+// The scenes are built and stepped through the public API, only the world
+// reset between runs uses the test helpers. There is no raycast API yet, so
+// raycast heavy scenes are not covered.
+
+// scenes that keep moving are rebuilt every resetEvery steps so long runs
+// don't end up measuring a settled world
+const resetEvery = 120
+
+func benchmarkSteps(b *testing.B, setup func(b *testing.B), reset bool) {
+	resetWorld(b)
+
+	var pairs, manifolds int
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i == 0 || reset && i%resetEvery == 0 {
+			b.StopTimer()
+			Close()
+			setup(b)
+			b.StartTimer()
+		}
+		UpdatePhysics(1.0 / 60)
+		s := GetStats()
+		pairs += s.CandidatePairs
+		manifolds += s.Manifolds
+	}
+	b.ReportMetric(float64(pairs)/float64(b.N), "pairs/step")
+	b.ReportMetric(float64(manifolds)/float64(b.N), "contacts/step")
+}
+
+// like mustBody, but skips the benchmark when the world can't hold its n bodies
+func fit(b testing.TB, n int) func(body *Body, err error) *Body {
+	return func(body *Body, err error) *Body {
+		b.Helper()
+		if errors.Is(err, ErrTooManyBodies) {
+			b.Skipf("%d bodies don't fit in the world", n)
+		}
+		return mustBody(b)(body, err)
+	}
+}
+
+// a static floor under a grid of n shapes of the given size, the grid is
+// about twice as wide as it is high
+func gridScene(b testing.TB, n int, size float32, create func(pos Vector) (*Body, error)) {
+	cols := int(math.Ceil(math.Sqrt(float64(n) * 2)))
+	rows := (n + cols - 1) / cols
+	spacing := size * 1.5
+	width := float32(cols) * spacing
+	floor := float32(rows)*spacing + 100
+
+	mustBody(b)(CreateBodyRectangle(NewVector(width/2, floor+20), width+200, 40, 1, true))
+	for i := 0; i < n; i++ {
+		x := spacing/2 + float32(i%cols)*spacing
+		y := floor - float32(rows-i/cols)*spacing
+		fit(b, n)(create(NewVector(x, y)))
+	}
+}
+
+func BenchmarkFallingCircles(b *testing.B) {
+	for _, n := range []int{100, 300, 1000, 5000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			benchmarkSteps(b, func(b *testing.B) {
+				gridScene(b, n, 10, func(pos Vector) (*Body, error) {
+					return CreateBodyCircle(pos, 5, 1, false)
+				})
+			}, true)
+		})
+	}
+}
+
+func BenchmarkPyramid(b *testing.B) {
+	for _, rows := range []int{10, 20, 30} {
+		b.Run(fmt.Sprint(rows), func(b *testing.B) {
+			benchmarkSteps(b, func(b *testing.B) {
+				const size = 20
+				mustBody(b)(CreateBodyRectangle(NewVector(500, 800), 1000, 40, 1, true))
+				for row := 0; row < rows; row++ {
+					for i := 0; i < rows-row; i++ {
+						x := 500 + (float32(i)-float32(rows-row-1)/2)*(size+1)
+						y := 780 - size/2 - float32(row)*(size+1)
+						fit(b, rows*(rows+1)/2)(CreateBodyRectangle(NewVector(x, y), size, size, 1, false))
+					}
+				}
+			}, true)
+		})
+	}
+}
+
+// a settled world where every body sleeps
+func BenchmarkSleeping(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			benchmarkSteps(b, func(b *testing.B) {
+				gridScene(b, n, 10, func(pos Vector) (*Body, error) {
+					return CreateBodyRectangle(pos, 10, 10, 1, false)
+				})
+				for _, body := range GetBodies() {
+					body.SetAwake(false)
+				}
+			}, false)
+		})
+	}
+}
+
+// every joint type on top of a settling pyramid
+func setupJointScene(tb testing.TB) {
+	must := mustBody(tb)
+	ground := must(CreateBodyRectangle(NewVector(500, 800), 1000, 40, 1, true))
+	for row := 0; row < 5; row++ {
+		for i := 0; i < 5-row; i++ {
+			x := 500 + (float32(i)-float32(5-row-1)/2)*21
+			must(CreateBodyRectangle(NewVector(x, 770-float32(row)*21), 20, 20, 1, false))
+		}
+	}
+
+	wheelA := must(CreateBodyCircle(NewVector(200, 300), 15, 1, false))
+	wheelB := must(CreateBodyCircle(NewVector(260, 300), 15, 1, false))
+	revoluteA, err := CreateRevoluteJoint(ground, wheelA, NewVector(200, 300))
+	noError(tb, err)
+	revoluteB, err := CreateRevoluteJoint(ground, wheelB, NewVector(260, 300))
+	noError(tb, err)
+	_, err = CreateGearJoint(revoluteA, revoluteB, 2)
+	noError(tb, err)
+
+	slider := must(CreateBodyRectangle(NewVector(800, 300), 40, 20, 1, false))
+	_, err = CreatePrismaticJoint(ground, slider, NewVector(800, 300), NewVector(1, 0))
+	noError(tb, err)
+
+	car := must(CreateBodyRectangle(NewVector(400, 500), 60, 20, 1, false))
+	wheel := must(CreateBodyCircle(NewVector(400, 530), 10, 1, false))
+	_, err = CreateWheelJoint(car, wheel, NewVector(400, 530), NewVector(0, 1), 4, 0.7)
+	noError(tb, err)
+
+	left := must(CreateBodyRectangle(NewVector(600, 400), 20, 20, 1, false))
+	right := must(CreateBodyRectangle(NewVector(700, 400), 20, 20, 2, false))
+	_, err = CreatePulleyJoint(left, right, NewVector(600, 200), NewVector(700, 200), NewVector(600, 400), NewVector(700, 400), 1)
+	noError(tb, err)
+
+	dragged := must(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	_, err = CreateMouseJoint(dragged, NewVector(150, 150), 100, 5, 0.7)
+	noError(tb, err)
+}
+
+// the steady state step reuses its storage and must not allocate
+func TestStepAllocations(t *testing.T) {
+	resetWorld(t)
+	setupJointScene(t)
+	UpdatePhysics(1.0 / 60)
+
+	if allocs := testing.AllocsPerRun(100, func() { UpdatePhysics(1.0 / 60) }); allocs != 0 {
+		t.Errorf("a step made %v allocations, expected 0", allocs)
+	}
+}
+
+func BenchmarkJoints(b *testing.B) {
+	benchmarkSteps(b, func(b *testing.B) { setupJointScene(b) }, true)
+}
+
+func BenchmarkWorkers(b *testing.B) {
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			SetWorkers(n)
+			b.Cleanup(func() { SetWorkers(1) })
+			benchmarkSteps(b, func(b *testing.B) {
+				gridScene(b, 1000, 20, func(pos Vector) (*Body, error) {
+					return CreateBodyRectangle(pos, 20, 20, 1, false)
+				})
+			}, true)
+		})
+	}
+}
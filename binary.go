@@ -0,0 +1,638 @@
+package phygo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// version of the binary format written by MarshalBinary. Version 1 stored full
+// precision positions in pixels, which doesn't round trip exactly, it can still be read.
+const binaryVersion = 2
+
+var (
+	snapshotMagic = [4]byte{'P', 'H', 'Y', 'S'}
+	bodyMagic     = [4]byte{'P', 'H', 'Y', 'B'}
+
+	errShortBuffer = errors.New("phygo: unexpected end of binary data")
+)
+
+const (
+	flagQuantized = 1 << iota
+)
+
+type binWriter struct {
+	buf []byte
+	// quantization steps, 0 stores full floats
+	posStep, velStep float32
+	// joints refer to bodies by their index in this list
+	jointBodies []*Body
+}
+
+func (w *binWriter) u8(v uint8) {
+	w.buf = append(w.buf, v)
+}
+
+func (w *binWriter) bool(v bool) {
+	if v {
+		w.u8(1)
+	} else {
+		w.u8(0)
+	}
+}
+
+func (w *binWriter) u32(v uint32) {
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, v)
+}
+
+func (w *binWriter) u64(v uint64) {
+	w.buf = binary.LittleEndian.AppendUint64(w.buf, v)
+}
+
+func (w *binWriter) i32(v int32) {
+	w.u32(uint32(v))
+}
+
+func (w *binWriter) f32(v float32) {
+	w.u32(math.Float32bits(v))
+}
+
+func (w *binWriter) vec(v Vector) {
+	w.f32(v.X)
+	w.f32(v.Y)
+}
+
+// quantized values are stored as varints, so small values take few bytes
+func (w *binWriter) quantized(v, step float32) {
+	if step > 0 {
+		w.buf = binary.AppendVarint(w.buf, int64(math.Round(float64(v/step))))
+	} else {
+		w.f32(v)
+	}
+}
+
+// positions are quantized in pixels, full precision ones are stored in units
+// so they round trip exactly
+func (w *binWriter) pos(v Vector) {
+	if w.posStep > 0 {
+		v = VectorMul(v, ppu)
+	}
+	w.quantized(v.X, w.posStep)
+	w.quantized(v.Y, w.posStep)
+}
+
+func (w *binWriter) vel(v Vector) {
+	w.quantized(v.X, w.velStep)
+	w.quantized(v.Y, w.velStep)
+}
+
+type binReader struct {
+	buf              []byte
+	err              error
+	version          uint8
+	posStep, velStep float32
+}
+
+// returns zeros once the data is exhausted, the error is checked by the caller
+func (r *binReader) next(n int) []byte {
+	if r.err == nil && len(r.buf) < n {
+		r.err = errShortBuffer
+	}
+	if r.err != nil {
+		return make([]byte, min(n, 8))
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *binReader) u8() uint8 {
+	return r.next(1)[0]
+}
+
+func (r *binReader) bool() bool {
+	return r.u8() != 0
+}
+
+func (r *binReader) u32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *binReader) u64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func (r *binReader) i32() int32 {
+	return int32(r.u32())
+}
+
+func (r *binReader) f32() float32 {
+	return math.Float32frombits(r.u32())
+}
+
+func (r *binReader) vec() Vector {
+	x := r.f32()
+	return NewVector(x, r.f32())
+}
+
+func (r *binReader) quantized(step float32) float32 {
+	if step > 0 {
+		if r.err != nil {
+			return 0
+		}
+		v, n := binary.Varint(r.buf)
+		if n <= 0 {
+			r.err = errShortBuffer
+			return 0
+		}
+		r.buf = r.buf[n:]
+		return float32(v) * step
+	}
+	return r.f32()
+}
+
+func (r *binReader) pos() Vector {
+	x := r.quantized(r.posStep)
+	v := NewVector(x, r.quantized(r.posStep))
+	if r.posStep > 0 || r.version < 2 {
+		v = NewVector(v.X/ppu, v.Y/ppu)
+	}
+	return v
+}
+
+func (r *binReader) vel() Vector {
+	x := r.quantized(r.velStep)
+	return NewVector(x, r.quantized(r.velStep))
+}
+
+func (w *binWriter) header(magic [4]byte) {
+	w.buf = append(w.buf, magic[:]...)
+	w.u8(binaryVersion)
+	if w.posStep > 0 || w.velStep > 0 {
+		w.u8(flagQuantized)
+		w.f32(w.posStep)
+		w.f32(w.velStep)
+	} else {
+		w.u8(0)
+	}
+}
+
+func (r *binReader) header(magic [4]byte) error {
+	if m := r.next(4); r.err == nil && string(m) != string(magic[:]) {
+		return fmt.Errorf("phygo: invalid binary data, expected %q header", magic[:])
+	}
+	if r.version = r.u8(); r.err == nil && (r.version < 1 || r.version > binaryVersion) {
+		return fmt.Errorf("phygo: unsupported binary version %d", r.version)
+	}
+	if flags := r.u8(); flags&flagQuantized != 0 {
+		r.posStep = r.f32()
+		r.velStep = r.f32()
+	}
+	return r.err
+}
+
+func (w *binWriter) world(ws worldState) {
+	w.vec(ws.gravity)
+	w.u32(uint32(ws.iterations))
+	w.f32(ws.fixedTimestep)
+	w.u32(uint32(ws.maxStepsPerFrame))
+	w.f32(ws.accumulator)
+	w.f32(ws.interpolationAlpha)
+	w.bool(ws.deterministic)
+	w.bool(ws.sleepEnabled)
+	w.f32(ws.linearSleepTolerance)
+	w.f32(ws.angularSleepTolerance)
+	w.f32(ws.timeToSleep)
+}
+
+func (r *binReader) world() worldState {
+	var ws worldState
+	ws.gravity = r.vec()
+	ws.iterations = int(r.u32())
+	ws.fixedTimestep = r.f32()
+	ws.maxStepsPerFrame = int(r.u32())
+	ws.accumulator = r.f32()
+	ws.interpolationAlpha = r.f32()
+	ws.deterministic = r.bool()
+	ws.sleepEnabled = r.bool()
+	ws.linearSleepTolerance = r.f32()
+	ws.angularSleepTolerance = r.f32()
+	ws.timeToSleep = r.f32()
+	return ws
+}
+
+func (w *binWriter) body(b *Body) {
+	w.i32(int32(b.Id))
+	w.u8(uint8(b.bodyType))
+	w.u8(uint8(b.ShapeType))
+	w.f32(b.radius)
+	w.f32(b.width)
+	w.f32(b.height)
+	w.f32(b.area)
+	w.f32(b.mass)
+	w.f32(b.inertia)
+	w.f32(b.restitution)
+	w.f32(b.staticFriction)
+	w.f32(b.dynamicFriction)
+
+	w.pos(b.position)
+	w.f32(b.Rotation)
+	w.vel(b.Velocity)
+	w.quantized(b.AngularVelocity, w.velStep)
+	w.vec(b.Force)
+	w.f32(b.Torque)
+	w.pos(b.prevPosition)
+	w.f32(b.prevRotation)
+
+	w.f32(b.LinearDamping)
+	w.f32(b.AngularDamping)
+	w.f32(b.GravityScale)
+	w.f32(b.MaxLinearSpeed)
+	w.f32(b.MaxAngularSpeed)
+
+	w.bool(b.RotationDisabled)
+	w.bool(b.IsOnGround)
+	w.bool(b.UseGravity)
+	w.bool(b.AllowSleep)
+	w.bool(b.awake)
+	w.f32(b.sleepTime)
+}
+
+func (r *binReader) body(b *Body) error {
+	*b = Body{}
+	b.Id = int(r.i32())
+	b.bodyType = BodyType(r.u8())
+	b.ShapeType = ShapeType(r.u8())
+	b.radius = r.f32()
+	b.width = r.f32()
+	b.height = r.f32()
+	b.area = r.f32()
+	b.mass = r.f32()
+	b.inertia = r.f32()
+	b.restitution = r.f32()
+	b.staticFriction = r.f32()
+	b.dynamicFriction = r.f32()
+
+	b.position = r.pos()
+	b.Rotation = r.f32()
+	b.Velocity = r.vel()
+	b.AngularVelocity = r.quantized(r.velStep)
+	b.Force = r.vec()
+	b.Torque = r.f32()
+	b.prevPosition = r.pos()
+	b.prevRotation = r.f32()
+
+	b.LinearDamping = r.f32()
+	b.AngularDamping = r.f32()
+	b.GravityScale = r.f32()
+	b.MaxLinearSpeed = r.f32()
+	b.MaxAngularSpeed = r.f32()
+
+	b.RotationDisabled = r.bool()
+	b.IsOnGround = r.bool()
+	b.UseGravity = r.bool()
+	b.AllowSleep = r.bool()
+	b.awake = r.bool()
+	b.sleepTime = r.f32()
+
+	if r.err != nil {
+		return r.err
+	}
+	if b.bodyType > DynamicBody || b.ShapeType > RectangleShape {
+		return fmt.Errorf("phygo: body %d has an invalid type", b.Id)
+	}
+
+	// derived state
+	b.updateMassData()
+	if b.ShapeType == RectangleShape {
+		b.verticesAtOrigin = createRectangleVertices(b.width, b.height)
+	}
+	b.transformUpdateRequired = true
+	b.aabbUpdateRequired = true
+	b.transformVertices()
+	b.updateAABB()
+	return nil
+}
+
+func (b *Body) MarshalBinary() ([]byte, error) {
+	w := binWriter{}
+	w.header(bodyMagic)
+	w.body(b)
+	return w.buf, nil
+}
+
+// Decodes into b, the body is not added to the world
+func (b *Body) UnmarshalBinary(data []byte) error {
+	r := binReader{buf: data}
+	if err := r.header(bodyMagic); err != nil {
+		return err
+	}
+	return r.body(b)
+}
+
+// Sets the quantization used by MarshalBinary, positions are rounded to
+// multiples of posStep pixels and velocities to multiples of velStep.
+// 0 keeps full precision.
+func (s *Snapshot) SetQuantization(posStep, velStep float32) {
+	s.posStep = posStep
+	s.velStep = velStep
+}
+
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	w := binWriter{posStep: s.posStep, velStep: s.velStep, jointBodies: s.bodies}
+	w.header(snapshotMagic)
+
+	w.world(s.world)
+
+	w.u32(uint32(len(s.bodyStates)))
+	for i := range s.bodyStates {
+		w.body(&s.bodyStates[i])
+	}
+
+	w.u32(uint32(len(s.jointStates)))
+	for _, j := range s.jointStates {
+		if err := w.joint(j, s.joints); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf, nil
+}
+
+// Decodes a snapshot written by MarshalBinary. Bodies and joints are bound to
+// the ones of the current world with the same id (and index for joints), so
+// pointers held by the game stay valid after RestoreSnapshot; the others are
+// created on restore. Contacts are not part of the binary format.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	r := binReader{buf: data}
+	if err := r.header(snapshotMagic); err != nil {
+		return err
+	}
+	posStep, velStep := r.posStep, r.velStep
+
+	var decoded Snapshot
+	decoded.posStep, decoded.velStep = posStep, velStep
+	decoded.world = r.world()
+
+	count := int(r.u32())
+	if r.err == nil && count > maxBodies {
+		return fmt.Errorf("phygo: snapshot has %d bodies, at most %d are supported", count, maxBodies)
+	}
+	decoded.bodyStates = make([]Body, count)
+	decoded.bodies = make([]*Body, count)
+	for i := range decoded.bodyStates {
+		if err := r.body(&decoded.bodyStates[i]); err != nil {
+			return err
+		}
+		decoded.bodies[i] = bodyById(decoded.bodyStates[i].Id)
+		if decoded.bodies[i] == nil {
+			decoded.bodies[i] = &Body{}
+		}
+	}
+
+	count = int(r.u32())
+	if r.err == nil && count > maxJoints {
+		return fmt.Errorf("phygo: snapshot has %d joints, at most %d are supported", count, maxJoints)
+	}
+	decoded.jointStates = make([]Joint, count)
+	decoded.joints = make([]Joint, count)
+	for i := range decoded.jointStates {
+		j, err := r.joint(decoded.bodies, decoded.joints[:i])
+		if err != nil {
+			return err
+		}
+		decoded.jointStates[i] = j
+
+		// reusing the live joint at the same place when it connects the same bodies
+		if i < jointCount && sameJoint(joints[i], j) {
+			decoded.joints[i] = joints[i]
+		} else {
+			decoded.joints[i] = j.copyInto(nil)
+		}
+	}
+
+	if r.err != nil {
+		return r.err
+	}
+	*s = decoded
+	return nil
+}
+
+func bodyById(id int) *Body {
+	for _, b := range bodies[:bodyCount] {
+		if b.Id == id {
+			return b
+		}
+	}
+	return nil
+}
+
+func sameJoint(a, b Joint) bool {
+	return a.GetType() == b.GetType() && a.GetBodyA() == b.GetBodyA() && a.GetBodyB() == b.GetBodyB()
+}
+
+func bodyIndex(b *Body, list []*Body) int32 {
+	for i, other := range list {
+		if other == b {
+			return int32(i)
+		}
+	}
+	return -1
+}
+
+func (r *binReader) bodyRef(list []*Body) *Body {
+	i := r.i32()
+	if i < 0 || int(i) >= len(list) {
+		if i != -1 && r.err == nil {
+			r.err = fmt.Errorf("phygo: joint refers to missing body %d", i)
+		}
+		return nil
+	}
+	return list[i]
+}
+
+// bodies are referenced by their index in the snapshot
+func (w *binWriter) joint(j Joint, list []Joint) error {
+	w.u8(uint8(j.GetType()))
+
+	var base *jointBase
+	switch j := j.(type) {
+	case *MouseJoint:
+		base = &j.jointBase
+	case *RevoluteJoint:
+		base = &j.jointBase
+	case *PrismaticJoint:
+		base = &j.jointBase
+	case *WheelJoint:
+		base = &j.jointBase
+	case *PulleyJoint:
+		base = &j.jointBase
+	case *GearJoint:
+		base = &j.jointBase
+	default:
+		return fmt.Errorf("phygo: can't encode joint of type %d", j.GetType())
+	}
+	w.bool(base.CollideConnected)
+	w.i32(bodyIndex(base.bodyA, w.jointBodies))
+	w.i32(bodyIndex(base.bodyB, w.jointBodies))
+
+	switch j := j.(type) {
+	case *MouseJoint:
+		w.vec(j.localAnchor)
+		w.vec(j.target)
+		w.f32(j.MaxForce)
+		w.f32(j.Frequency)
+		w.f32(j.DampingRatio)
+		w.vec(j.impulse)
+	case *RevoluteJoint:
+		w.vec(j.localAnchorA)
+		w.vec(j.localAnchorB)
+		w.f32(j.referenceAngle)
+		w.bool(j.EnableMotor)
+		w.f32(j.MotorSpeed)
+		w.f32(j.MaxMotorTorque)
+		w.f32(j.motorImpulse)
+	case *PrismaticJoint:
+		w.vec(j.localAnchorA)
+		w.vec(j.localAnchorB)
+		w.vec(j.localAxisA)
+		w.f32(j.referenceAngle)
+		w.bool(j.EnableMotor)
+		w.f32(j.MotorSpeed)
+		w.f32(j.MaxMotorForce)
+		w.f32(j.motorImpulse)
+	case *WheelJoint:
+		w.vec(j.localAnchorA)
+		w.vec(j.localAnchorB)
+		w.vec(j.localAxisA)
+		w.f32(j.Frequency)
+		w.f32(j.DampingRatio)
+		w.bool(j.EnableMotor)
+		w.f32(j.MotorSpeed)
+		w.f32(j.MaxMotorTorque)
+		w.f32(j.springImp)
+		w.f32(j.motorImpulse)
+	case *PulleyJoint:
+		w.vec(j.groundAnchorA)
+		w.vec(j.groundAnchorB)
+		w.vec(j.localAnchorA)
+		w.vec(j.localAnchorB)
+		w.f32(j.constant)
+		w.f32(j.ratio)
+	case *GearJoint:
+		w.i32(int32(jointIndexIn(j.joint1, list)))
+		w.i32(int32(jointIndexIn(j.joint2, list)))
+		w.f32(j.constant)
+		w.f32(j.ratio)
+	}
+	return nil
+}
+
+func jointIndexIn(j Joint, list []Joint) int {
+	for i, other := range list {
+		if other == j {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *binReader) joint(bodyList []*Body, earlier []Joint) (Joint, error) {
+	jointType := JointType(r.u8())
+	base := jointBase{CollideConnected: r.bool()}
+	base.bodyA = r.bodyRef(bodyList)
+	base.bodyB = r.bodyRef(bodyList)
+
+	var j Joint
+	switch jointType {
+	case MouseJointType:
+		j = &MouseJoint{
+			jointBase:    base,
+			localAnchor:  r.vec(),
+			target:       r.vec(),
+			MaxForce:     r.f32(),
+			Frequency:    r.f32(),
+			DampingRatio: r.f32(),
+			impulse:      r.vec(),
+		}
+	case RevoluteJointType:
+		j = &RevoluteJoint{
+			jointBase:      base,
+			localAnchorA:   r.vec(),
+			localAnchorB:   r.vec(),
+			referenceAngle: r.f32(),
+			EnableMotor:    r.bool(),
+			MotorSpeed:     r.f32(),
+			MaxMotorTorque: r.f32(),
+			motorImpulse:   r.f32(),
+		}
+	case PrismaticJointType:
+		j = &PrismaticJoint{
+			jointBase:      base,
+			localAnchorA:   r.vec(),
+			localAnchorB:   r.vec(),
+			localAxisA:     r.vec(),
+			referenceAngle: r.f32(),
+			EnableMotor:    r.bool(),
+			MotorSpeed:     r.f32(),
+			MaxMotorForce:  r.f32(),
+			motorImpulse:   r.f32(),
+		}
+	case WheelJointType:
+		j = &WheelJoint{
+			jointBase:      base,
+			localAnchorA:   r.vec(),
+			localAnchorB:   r.vec(),
+			localAxisA:     r.vec(),
+			Frequency:      r.f32(),
+			DampingRatio:   r.f32(),
+			EnableMotor:    r.bool(),
+			MotorSpeed:     r.f32(),
+			MaxMotorTorque: r.f32(),
+			springImp:      r.f32(),
+			motorImpulse:   r.f32(),
+		}
+	case PulleyJointType:
+		j = &PulleyJoint{
+			jointBase:     base,
+			groundAnchorA: r.vec(),
+			groundAnchorB: r.vec(),
+			localAnchorA:  r.vec(),
+			localAnchorB:  r.vec(),
+			constant:      r.f32(),
+			ratio:         r.f32(),
+		}
+	case GearJointType:
+		index1, index2 := int(r.i32()), int(r.i32())
+		constant, ratio := r.f32(), r.f32()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if index1 < 0 || index1 >= len(earlier) || index2 < 0 || index2 >= len(earlier) ||
+			!isGearCompatible(earlier[index1]) || !isGearCompatible(earlier[index2]) {
+			return nil, errors.New("phygo: gear joint refers to an invalid joint")
+		}
+		joint1, joint2 := earlier[index1], earlier[index2]
+		j = &GearJoint{
+			jointBase: jointBase{bodyA: joint1.GetBodyB(), bodyB: joint2.GetBodyB(), CollideConnected: base.CollideConnected},
+			joint1:    joint1,
+			joint2:    joint2,
+			bodyC:     joint1.GetBodyA(),
+			bodyD:     joint2.GetBodyA(),
+			constant:  constant,
+			ratio:     ratio,
+		}
+	default:
+		if r.err == nil {
+			return nil, fmt.Errorf("phygo: unknown joint type %d", jointType)
+		}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	if jointType != GearJointType && (base.bodyB == nil || (jointType != MouseJointType && base.bodyA == nil)) {
+		return nil, fmt.Errorf("phygo: %s joint is missing a body", jointTypeNames[jointType])
+	}
+	return j, nil
+}
@@ -0,0 +1,149 @@
+package phygo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestBodyBinaryRoundTrip(t *testing.T) {
+	resetWorld(t)
+	b := mustBody(t)(CreateBodyRectangle(NewVector(123.4, 80.7), 30, 20, 2, false))
+	b.RotateTo(0.5)
+	b.Velocity = NewVector(1.1, -2.3)
+	b.AngularVelocity = 0.25
+	b.SetRestitution(0.4)
+
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Body
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.StateHash() != b.StateHash() || got.GetVertices() != b.GetVertices() ||
+		got.restitution != b.restitution || got.mass != b.mass {
+		t.Errorf("decoded body %+v, expected %+v", got, *b)
+	}
+}
+
+// a full precision snapshot continues exactly like the world it was taken from
+func TestSnapshotBinaryRoundTrip(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	runHashes(100)
+
+	data, err := TakeSnapshot(nil).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := StateHash()
+	want := runHashes(500)
+
+	var s Snapshot
+	if err := s.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	RestoreSnapshot(&s)
+	if got := StateHash(); got != hash {
+		t.Fatalf("restored hash %x, expected %x", got, hash)
+	}
+	compareHashes(t, want, runHashes(500))
+}
+
+func TestSnapshotQuantization(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	runHashes(30)
+
+	const posStep, velStep = 0.1, 0.01
+	s := TakeSnapshot(nil)
+	s.SetQuantization(posStep, velStep)
+	quantized, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := TakeSnapshot(nil).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quantized) >= len(full) {
+		t.Errorf("quantized snapshot takes %d bytes, full precision %d", len(quantized), len(full))
+	}
+
+	want := make(map[int]Body)
+	for _, b := range GetBodies() {
+		want[b.Id] = *b
+	}
+	var decoded Snapshot
+	if err := decoded.UnmarshalBinary(quantized); err != nil {
+		t.Fatal(err)
+	}
+	RestoreSnapshot(&decoded)
+	for _, b := range GetBodies() {
+		w := want[b.Id]
+		wantPos := w.GetPos()
+		if pos := b.GetPos(); math.Abs(float64(pos.X-wantPos.X)) > posStep/2+1e-3 ||
+			math.Abs(float64(pos.Y-wantPos.Y)) > posStep/2+1e-3 {
+			t.Errorf("body %d at %v, expected %v", b.Id, pos, wantPos)
+		}
+		if math.Abs(float64(b.Velocity.X-w.Velocity.X)) > velStep/2+1e-5 ||
+			math.Abs(float64(b.Velocity.Y-w.Velocity.Y)) > velStep/2+1e-5 {
+			t.Errorf("body %d moves at %v, expected %v", b.Id, b.Velocity, w.Velocity)
+		}
+	}
+}
+
+// version 1 stored full precision positions in pixels
+func TestBinaryVersion1(t *testing.T) {
+	resetWorld(t)
+	b := mustBody(t)(CreateBodyCircle(NewVector(100, 200), 10, 1, false))
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rewriting the position, stored in units, as version 1 pixels
+	units := binary.LittleEndian.AppendUint32(nil, math.Float32bits(100/ppu))
+	units = binary.LittleEndian.AppendUint32(units, math.Float32bits(200/ppu))
+	at := bytes.Index(data, units)
+	if at < 0 {
+		t.Fatal("position not found in the encoded body")
+	}
+	old := append([]byte{}, data...)
+	old[4] = 1
+	binary.LittleEndian.PutUint32(old[at:], math.Float32bits(100))
+	binary.LittleEndian.PutUint32(old[at+4:], math.Float32bits(200))
+
+	var got Body
+	if err := got.UnmarshalBinary(old); err != nil {
+		t.Fatal(err)
+	}
+	if pos := got.GetPos(); pos != NewVector(100, 200) {
+		t.Errorf("decoded position %v, expected (100, 200)", pos)
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	resetWorld(t)
+	data, err := TakeSnapshot(nil).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Snapshot
+	if err := s.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("decoded truncated data")
+	}
+	future := append([]byte{}, data...)
+	future[4] = binaryVersion + 1
+	if err := s.UnmarshalBinary(future); err == nil {
+		t.Error("decoded an unknown version")
+	}
+	if err := s.UnmarshalBinary([]byte("PHYB\x02\x00")); err == nil {
+		t.Error("decoded a body as a snapshot")
+	}
+}
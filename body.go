@@ -1,6 +1,12 @@
 package phygo
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrTooManyBodies = errors.New("phygo: too many bodies")
 
 type ShapeType int
 
@@ -9,6 +15,17 @@ const (
 	RectangleShape
 )
 
+type BodyType int
+
+const (
+	// never moves and has infinite mass
+	StaticBody BodyType = iota
+	// moved only by its velocity, has infinite mass and pushes dynamic bodies
+	KinematicBody
+	// moved by forces, gravity and collisions
+	DynamicBody
+)
+
 type Body struct {
 	Id int
 
@@ -17,15 +34,24 @@ type Body struct {
 	Rotation        float32
 	AngularVelocity float32
 	Force           Vector
+	Torque          float32
+
+	LinearDamping  float32 // velocity lost per second
+	AngularDamping float32
+	GravityScale   float32
+	// speed limits in Velocity units, 0 means no limit
+	MaxLinearSpeed  float32
+	MaxAngularSpeed float32
 
 	mass, invMass, restitution      float32
 	area, inertia, invInertia       float32
 	staticFriction, dynamicFriction float32
 
-	IsStatic         bool
+	bodyType         BodyType
 	RotationDisabled bool
 	IsOnGround       bool
 	UseGravity       bool
+	AllowSleep       bool
 	ShapeType        ShapeType
 	// used for circle shapes
 	radius float32
@@ -39,9 +65,35 @@ type Body struct {
 
 	aabb               AABB
 	aabbUpdateRequired bool
+
+	awake       bool
+	sleepTime   float32
+	islandIndex int
+
+	// transform before the last fixed step, used for interpolation
+	prevPosition Vector
+	prevRotation float32
+}
+
+// Returns an error when the radius isn't positive, the density of a dynamic
+// body isn't positive, a value isn't finite or the world is full
+func CreateBodyCircle(pos Vector, radius, density float32, isStatic bool) (*Body, error) {
+	return createCircle(pos, radius, density, bodyTypeFromStatic(isStatic))
+}
+
+// Creates a circle moved only by its velocity, the density is used if it is
+// made dynamic later
+func CreateKinematicCircle(pos Vector, radius, density float32) (*Body, error) {
+	return createCircle(pos, radius, density, KinematicBody)
 }
 
-func CreateBodyCircle(pos Vector, radius, density float32, isStatic bool) *Body {
+func createCircle(pos Vector, radius, density float32, bodyType BodyType) (*Body, error) {
+	if err := checkBodyInput(pos, density, bodyType); err != nil {
+		return nil, err
+	}
+	if err := checkCircle(radius); err != nil {
+		return nil, err
+	}
 	radius /= ppu
 
 	newBody := &Body{
@@ -49,10 +101,13 @@ func CreateBodyCircle(pos Vector, radius, density float32, isStatic bool) *Body
 		restitution:      0.0,
 		staticFriction:   0.6,
 		dynamicFriction:  0.3,
-		IsStatic:         isStatic,
+		bodyType:         bodyType,
+		awake:            bodyType != StaticBody,
 		IsOnGround:       false,
 		RotationDisabled: false,
 		UseGravity:       true,
+		AllowSleep:       true,
+		GravityScale:     1,
 		ShapeType:        CircleShape,
 		radius:           radius,
 	}
@@ -60,21 +115,34 @@ func CreateBodyCircle(pos Vector, radius, density float32, isStatic bool) *Body
 	newBody.area = radius * radius * math.Pi
 	newBody.mass = newBody.area * density
 	newBody.inertia = (newBody.mass * radius * radius) / 2
-	if !newBody.IsStatic {
-		newBody.invMass = 1 / newBody.mass
-		newBody.invInertia = 1 / newBody.inertia
-	} else {
-		newBody.invMass = 0.0
-		newBody.invInertia = 0.0
-	}
+	newBody.updateMassData()
+	newBody.savePreviousTransform()
 	newBody.transformUpdateRequired = true
 	newBody.aabbUpdateRequired = true
 	addBody(newBody)
 
-	return newBody
+	return newBody, nil
+}
+
+// Returns an error when the width or height isn't positive, the density of a
+// dynamic body isn't positive, a value isn't finite or the world is full
+func CreateBodyRectangle(pos Vector, width, height, density float32, isStatic bool) (*Body, error) {
+	return createRectangle(pos, width, height, density, bodyTypeFromStatic(isStatic))
+}
+
+// Creates a rectangle moved only by its velocity, the density is used if it is
+// made dynamic later
+func CreateKinematicRectangle(pos Vector, width, height, density float32) (*Body, error) {
+	return createRectangle(pos, width, height, density, KinematicBody)
 }
 
-func CreateBodyRectangle(pos Vector, width, height, density float32, isStatic bool) *Body {
+func createRectangle(pos Vector, width, height, density float32, bodyType BodyType) (*Body, error) {
+	if err := checkBodyInput(pos, density, bodyType); err != nil {
+		return nil, err
+	}
+	if err := checkRectangle(width, height); err != nil {
+		return nil, err
+	}
 	width /= ppu
 	height /= ppu
 
@@ -83,10 +151,13 @@ func CreateBodyRectangle(pos Vector, width, height, density float32, isStatic bo
 		restitution:      0.0,
 		staticFriction:   0.6,
 		dynamicFriction:  0.3,
-		IsStatic:         isStatic,
+		bodyType:         bodyType,
+		awake:            bodyType != StaticBody,
 		IsOnGround:       false,
 		RotationDisabled: false,
 		UseGravity:       true,
+		AllowSleep:       true,
+		GravityScale:     1,
 		ShapeType:        RectangleShape,
 		width:            width,
 		height:           height,
@@ -94,20 +165,168 @@ func CreateBodyRectangle(pos Vector, width, height, density float32, isStatic bo
 	newBody.area = height * width
 	newBody.mass = newBody.area * density
 	newBody.inertia = newBody.mass / 12 * (height*height + width*width)
-	if !newBody.IsStatic {
-		newBody.invMass = 1 / newBody.mass
-		newBody.invInertia = 1 / newBody.inertia
-	} else {
-		newBody.invMass = 0.0
-		newBody.invInertia = 0.0
-	}
+	newBody.updateMassData()
 
 	newBody.verticesAtOrigin = createRectangleVertices(width, height)
+	newBody.savePreviousTransform()
 	newBody.transformUpdateRequired = true
 	newBody.aabbUpdateRequired = true
 	addBody(newBody)
 
-	return newBody
+	return newBody, nil
+}
+
+// Creates static thin rectangles of the given thickness along the points,
+// lengthened so neighbouring edges overlap at the corners. The last point is
+// joined to the first when closed. Zero length edges are skipped.
+func CreateChain(points []Vector, thickness float32, closed bool) ([]*Body, error) {
+	count := len(points) - 1
+	if closed && len(points) > 2 {
+		count = len(points)
+	}
+
+	var chain []*Body
+	for i := 0; i < count; i++ {
+		a, b := points[i], points[(i+1)%len(points)]
+		delta := VectorSubtract(b, a)
+		length := VectorLen(delta)
+		if length == 0 {
+			continue
+		}
+		edge, err := CreateBodyRectangle(VectorLerp(a, b, 0.5), length+thickness, thickness, 1, true)
+		if err != nil {
+			for _, created := range chain {
+				RemoveBody(created)
+			}
+			return nil, err
+		}
+		edge.RotateTo(float32(math.Atan2(float64(delta.Y), float64(delta.X))))
+		chain = append(chain, edge)
+	}
+	return chain, nil
+}
+
+func isInvalid(v float32) bool {
+	return math.IsNaN(float64(v)) || math.IsInf(float64(v), 0)
+}
+
+func checkBodyInput(pos Vector, density float32, bodyType BodyType) error {
+	if err := checkBodyValues(pos, density, bodyType); err != nil {
+		return err
+	}
+	if bodyCount >= maxBodies {
+		return ErrTooManyBodies
+	}
+	return nil
+}
+
+// the checks of checkBodyInput that don't depend on the world
+func checkBodyValues(pos Vector, density float32, bodyType BodyType) error {
+	if isInvalid(pos.X) || isInvalid(pos.Y) {
+		return fmt.Errorf("phygo: body position must be finite, got %v", pos)
+	}
+	if isInvalid(density) {
+		return fmt.Errorf("phygo: body density must be finite, got %v", density)
+	}
+	if bodyType == DynamicBody && !(density > 0) {
+		return fmt.Errorf("phygo: dynamic bodies need a positive density, got %v", density)
+	}
+	return nil
+}
+
+func checkCircle(radius float32) error {
+	if !(radius > 0) || isInvalid(radius) {
+		return fmt.Errorf("phygo: circle radius must be positive, got %v", radius)
+	}
+	return nil
+}
+
+func checkRectangle(width, height float32) error {
+	if !(width > 0) || !(height > 0) || isInvalid(width) || isInvalid(height) {
+		return fmt.Errorf("phygo: rectangle size must be positive, got %vx%v", width, height)
+	}
+	return nil
+}
+
+func bodyTypeFromStatic(isStatic bool) BodyType {
+	if isStatic {
+		return StaticBody
+	}
+	return DynamicBody
+}
+
+// only dynamic bodies respond to impulses, the others act as if their mass was infinite
+func (b *Body) updateMassData() {
+	// a massless body made dynamic with SetBodyType would spread Inf to everything it touches
+	if b.bodyType == DynamicBody && b.mass > 0 {
+		b.invMass = 1 / b.mass
+		b.invInertia = 1 / b.inertia
+	} else {
+		b.invMass = 0.0
+		b.invInertia = 0.0
+	}
+}
+
+func (b *Body) SetBodyType(bodyType BodyType) {
+	b.bodyType = bodyType
+	if bodyType == StaticBody {
+		b.Velocity = VectorZero()
+		b.AngularVelocity = 0
+		b.awake = false
+	} else {
+		b.SetAwake(true)
+	}
+	b.clearForces()
+	b.updateMassData()
+}
+
+func (b *Body) GetBodyType() BodyType {
+	return b.bodyType
+}
+
+func (b *Body) IsStatic() bool {
+	return b.bodyType == StaticBody
+}
+
+func (b *Body) IsKinematic() bool {
+	return b.bodyType == KinematicBody
+}
+
+func (b *Body) IsDynamic() bool {
+	return b.bodyType == DynamicBody
+}
+
+// static bodies are never awake
+func (b *Body) IsAwake() bool {
+	return b.awake
+}
+
+func (b *Body) isSleeping() bool {
+	return !b.awake && b.bodyType != StaticBody
+}
+
+func (b *Body) SetAwake(awake bool) {
+	if b.bodyType == StaticBody || b.awake == awake {
+		return
+	}
+	b.awake = awake
+	b.sleepTime = 0
+
+	if awake {
+		if onWake != nil {
+			onWake(b)
+		}
+	} else {
+		// kinematic bodies keep their velocity, it's set by the user and not by forces
+		if b.bodyType != KinematicBody {
+			b.Velocity = VectorZero()
+			b.AngularVelocity = 0
+		}
+		b.clearForces()
+		if onSleep != nil {
+			onSleep(b)
+		}
+	}
 }
 
 func createRectangleVertices(width, height float32) [4]Vector {
@@ -135,6 +354,26 @@ func (b *Body) SetDynamicFriction(dFriction float32) {
 	b.dynamicFriction = ClampFloat(dFriction, minFriction, maxFriction)
 }
 
+func (b *Body) GetRestitution() float32 {
+	return b.restitution
+}
+
+func (b *Body) GetStaticFriction() float32 {
+	return b.staticFriction
+}
+
+func (b *Body) GetDynamicFriction() float32 {
+	return b.dynamicFriction
+}
+
+// inverse inertia as seen by the solver, zero when rotation is disabled
+func (b *Body) getInvInertia() float32 {
+	if b.RotationDisabled {
+		return 0
+	}
+	return b.invInertia
+}
+
 func (b *Body) transformVertices() {
 	if b.transformUpdateRequired {
 		transform := NewTransform(b.position.X, b.position.Y, b.Rotation)
@@ -147,33 +386,56 @@ func (b *Body) transformVertices() {
 }
 
 func (b *Body) step(time float32, iteration int) {
-	if b.IsStatic {
+	if b.bodyType == StaticBody {
 		return
 	}
 
 	time /= float32(iteration)
 
-	acceleration := VectorMul(b.Force, b.invMass)
-	b.Velocity.AddValue(VectorMul(acceleration, time))
-	if b.UseGravity {
-		b.Velocity.AddValue(VectorMul(gravity, time))
+	// kinematic bodies ignore forces and gravity
+	if b.bodyType == DynamicBody {
+		acceleration := VectorMul(b.Force, b.invMass)
+		b.Velocity.AddValue(VectorMul(acceleration, time))
+		if b.UseGravity {
+			b.Velocity.AddValue(VectorMul(gravity, b.GravityScale*time))
+		}
+		if !b.RotationDisabled {
+			b.AngularVelocity += float32(b.Torque * b.invInertia * time)
+		}
+
+		b.Velocity = VectorMul(b.Velocity, 1/(1+float32(time*b.LinearDamping)))
+		b.AngularVelocity *= 1 / (1 + float32(time*b.AngularDamping))
+
+		if b.MaxLinearSpeed > 0 && VectorLenSqr(b.Velocity) > b.MaxLinearSpeed*b.MaxLinearSpeed {
+			b.Velocity = VectorMul(VectorNormalize(b.Velocity), b.MaxLinearSpeed)
+		}
+		if b.MaxAngularSpeed > 0 {
+			b.AngularVelocity = ClampFloat(b.AngularVelocity, -b.MaxAngularSpeed, b.MaxAngularSpeed)
+		}
 	}
 	b.position.AddValue(VectorMul(b.Velocity, ppu*time))
 	if !b.RotationDisabled {
-		b.Rotation += b.AngularVelocity * ppu * time
+		b.Rotation += float32(b.AngularVelocity * ppu * time)
 	}
 
 	if !VectorNearlyEqual(b.Velocity, VectorZero()) || !NearlyEqual(b.Rotation, 0.0) {
 		b.transformUpdateRequired = true
 		b.aabbUpdateRequired = true
 	}
+}
 
+// forces are accumulated during a frame and cleared once UpdatePhysics has run a step
+func (b *Body) clearForces() {
 	b.Force = VectorZero()
+	b.Torque = 0
 }
 
 func (b *Body) Move(deltaPos Vector) {
+	b.SetAwake(true)
 	b.position.AddValue(VectorMul(deltaPos, 1/float32(ppu)))
+	b.prevPosition = b.position
 	b.transformUpdateRequired = true
+	b.aabbUpdateRequired = true
 }
 
 func (b *Body) move(deltaPos Vector) {
@@ -183,25 +445,83 @@ func (b *Body) move(deltaPos Vector) {
 }
 
 func (b *Body) MoveTo(newPos Vector) {
+	b.SetAwake(true)
 	b.position = VectorMul(newPos, 1/float32(ppu))
+	b.prevPosition = b.position
 	b.transformUpdateRequired = true
 	b.aabbUpdateRequired = true
 }
 
 func (b *Body) Rotate(amount float32) {
+	b.SetAwake(true)
 	b.Rotation += amount
 	b.transformUpdateRequired = true
 	b.aabbUpdateRequired = true
 }
 
 func (b *Body) RotateTo(amount float32) {
+	b.SetAwake(true)
 	b.Rotation = amount
+	b.prevRotation = amount
 	b.transformUpdateRequired = true
 	b.aabbUpdateRequired = true
 }
 
+// Adds a force acting on the center of the body
 func (b *Body) ApplyForce(amount Vector) {
-	b.Force = amount
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	b.Force.AddValue(amount)
+}
+
+// Adds a force acting on a world point in pixels, producing torque when off center
+func (b *Body) ApplyForceAtPoint(amount, point Vector) {
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	r := VectorSubtract(VectorMul(point, 1/float32(ppu)), b.position)
+	b.Force.AddValue(amount)
+	b.Torque += VectorCrossProduct(r, amount)
+}
+
+func (b *Body) ApplyTorque(amount float32) {
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	b.Torque += amount
+}
+
+// Changes the velocity immediately
+func (b *Body) ApplyLinearImpulse(impulse Vector) {
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	b.Velocity.AddValue(VectorMul(impulse, b.invMass))
+}
+
+// Changes the velocity immediately, impulse is applied on a world point in pixels
+func (b *Body) ApplyLinearImpulseAtPoint(impulse, point Vector) {
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	r := VectorSubtract(VectorMul(point, 1/float32(ppu)), b.position)
+	b.Velocity.AddValue(VectorMul(impulse, b.invMass))
+	b.AngularVelocity += float32(VectorCrossProduct(r, impulse) * b.getInvInertia())
+}
+
+// Changes the angular velocity immediately
+func (b *Body) ApplyAngularImpulse(impulse float32) {
+	if b.bodyType != DynamicBody {
+		return
+	}
+	b.SetAwake(true)
+	b.AngularVelocity += float32(impulse * b.getInvInertia())
 }
 
 func (b *Body) updateAABB() {
@@ -271,6 +591,21 @@ func (b *Body) GetPos() Vector {
 	return VectorMul(b.position, ppu)
 }
 
+func (b *Body) savePreviousTransform() {
+	b.prevPosition = b.position
+	b.prevRotation = b.Rotation
+}
+
+// Returns the position between the last two fixed steps, for smooth rendering
+func (b *Body) GetInterpolatedPos() Vector {
+	return VectorMul(VectorLerp(b.prevPosition, b.position, interpolationAlpha), ppu)
+}
+
+// Returns the rotation between the last two fixed steps, for smooth rendering
+func (b *Body) GetInterpolatedRotation() float32 {
+	return b.prevRotation + float32((b.Rotation-b.prevRotation)*interpolationAlpha)
+}
+
 func (b *Body) GetVertices() [4]Vector {
 	b.transformVertices()
 
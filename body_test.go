@@ -0,0 +1,317 @@
+package phygo
+
+import (
+	"math"
+	"testing"
+)
+
+// a kinematic platform keeps its velocity under a falling box
+func TestKinematicBody(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	platform := must(CreateKinematicRectangle(NewVector(300, 400), 200, 20, 1))
+	box := must(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	if !platform.IsKinematic() || !platform.IsAwake() {
+		t.Fatalf("platform type %v, awake %v, expected an awake kinematic body", platform.GetBodyType(), platform.IsAwake())
+	}
+	platform.Velocity = NewVector(0.02, 0)
+
+	for step := 0; step < 120; step++ {
+		UpdatePhysics(1.0 / 60)
+	}
+	if pos := platform.GetPos(); pos.Y != 400 || pos.X <= 300 {
+		t.Errorf("platform at %v, expected it to move right along y=400", pos)
+	}
+	if platform.Velocity != NewVector(0.02, 0) {
+		t.Errorf("platform velocity %v, expected (0.02, 0)", platform.Velocity)
+	}
+	if bottom := box.GetAABB().Max.Y; bottom > platform.GetAABB().Min.Y+1 {
+		t.Errorf("the box fell %v pixels into the platform", bottom-platform.GetAABB().Min.Y)
+	}
+
+	if _, err := CreateKinematicCircle(NewVector(0, 0), 10, 0); err != nil {
+		t.Errorf("a massless kinematic circle failed: %v", err)
+	}
+}
+
+// every fixed step of a frame applies the forces of that frame, and a frame
+// too short for a step keeps them for the next one
+func TestForcesLastTheWholeFrame(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	const timestep = 0.25
+	SetFixedTimestep(timestep, 4)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	force := NewVector(0.5, 0)
+	step := force.X * box.invMass * timestep
+
+	box.ApplyForce(force)
+	UpdatePhysics(2 * timestep)
+	if want := 2 * step; math.Abs(float64(box.Velocity.X-want)) > 1e-6 {
+		t.Errorf("velocity %v after two steps, expected %v", box.Velocity.X, want)
+	}
+	if box.Force != VectorZero() {
+		t.Errorf("force %v after the frame, expected it cleared", box.Force)
+	}
+
+	box.ApplyForce(force)
+	UpdatePhysics(timestep / 2)
+	if box.Force != force {
+		t.Errorf("force %v after a frame without steps, expected %v", box.Force, force)
+	}
+	UpdatePhysics(timestep / 2)
+	if want := 3 * step; math.Abs(float64(box.Velocity.X-want)) > 1e-6 {
+		t.Errorf("velocity %v after the third step, expected %v", box.Velocity.X, want)
+	}
+}
+
+func nearlyEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) <= 1e-5*math.Max(1, math.Abs(float64(b)))
+}
+
+// a force below the center turns the body, one through the center doesn't
+func TestApplyForceAtPoint(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	force := NewVector(0.5, 0)
+
+	box.ApplyForceAtPoint(force, NewVector(300, 300))
+	if box.Torque != 0 || box.Force != force {
+		t.Errorf("force %v and torque %v through the center, expected %v and 0", box.Force, box.Torque, force)
+	}
+	box.Force = VectorZero()
+
+	// 10 pixels below the center, r = (0, 0.2) units
+	box.ApplyForceAtPoint(force, NewVector(300, 310))
+	if want := VectorCrossProduct(NewVector(0, 10/float32(ppu)), force); !nearlyEqual(box.Torque, want) || box.Force != force {
+		t.Errorf("force %v and torque %v, expected %v and %v", box.Force, box.Torque, force, want)
+	}
+	UpdatePhysics(1.0 / 60)
+	if box.AngularVelocity >= 0 {
+		t.Errorf("angular velocity %v, expected a push below the center to turn the box counterclockwise", box.AngularVelocity)
+	}
+
+	box.ApplyTorque(0.25)
+	if box.Torque != 0.25 {
+		t.Errorf("torque %v, expected 0.25", box.Torque)
+	}
+}
+
+func TestImpulses(t *testing.T) {
+	resetWorld(t)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	impulse := NewVector(0.1, -0.2)
+
+	box.ApplyLinearImpulse(impulse)
+	if want := VectorMul(impulse, box.invMass); box.Velocity != want || box.AngularVelocity != 0 {
+		t.Errorf("velocity %v and angular velocity %v, expected %v and 0", box.Velocity, box.AngularVelocity, want)
+	}
+
+	box.Velocity = VectorZero()
+	box.ApplyLinearImpulseAtPoint(NewVector(0.1, 0), NewVector(300, 290))
+	wantAngular := VectorCrossProduct(NewVector(0, -10/float32(ppu)), NewVector(0.1, 0)) * box.invInertia
+	if box.Velocity != NewVector(0.1*box.invMass, 0) || !nearlyEqual(box.AngularVelocity, wantAngular) {
+		t.Errorf("velocity %v and angular velocity %v, expected %v and %v", box.Velocity, box.AngularVelocity, NewVector(0.1*box.invMass, 0), wantAngular)
+	}
+
+	box.AngularVelocity = 0
+	box.ApplyAngularImpulse(0.3)
+	if want := 0.3 * box.invInertia; !nearlyEqual(box.AngularVelocity, want) {
+		t.Errorf("angular velocity %v, expected %v", box.AngularVelocity, want)
+	}
+
+	// impulses wake sleeping bodies
+	box.SetAwake(false)
+	box.ApplyLinearImpulse(impulse)
+	if !box.IsAwake() {
+		t.Error("an impulse didn't wake the body")
+	}
+}
+
+// only dynamic bodies respond to forces and impulses
+func TestForcesIgnoredByStaticAndKinematic(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	ground := must(CreateBodyRectangle(NewVector(300, 500), 200, 20, 1, true))
+	platform := must(CreateKinematicRectangle(NewVector(300, 300), 100, 20, 1))
+	platform.Velocity = NewVector(0.01, 0)
+
+	for _, b := range []*Body{ground, platform} {
+		velocity := b.Velocity
+		b.ApplyForce(NewVector(1, 1))
+		b.ApplyForceAtPoint(NewVector(1, 1), NewVector(0, 0))
+		b.ApplyTorque(1)
+		b.ApplyLinearImpulse(NewVector(1, 1))
+		b.ApplyLinearImpulseAtPoint(NewVector(1, 1), NewVector(0, 0))
+		b.ApplyAngularImpulse(1)
+		if b.Force != VectorZero() || b.Torque != 0 || b.Velocity != velocity || b.AngularVelocity != 0 {
+			t.Errorf("%v body: force %v, torque %v, velocity %v, angular velocity %v, expected them unchanged",
+				b.GetBodyType(), b.Force, b.Torque, b.Velocity, b.AngularVelocity)
+		}
+	}
+	if ground.IsAwake() {
+		t.Error("a force woke a static body")
+	}
+}
+
+// damping slows bodies down exponentially, by e after 1/damping seconds
+func TestDamping(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	box.AllowSleep = false
+	box.LinearDamping = 2
+	box.AngularDamping = 1
+	box.Velocity = NewVector(0.1, 0)
+	box.AngularVelocity = 0.1
+
+	stepFrames(30) // half a second
+	if want := 0.1 * math.Exp(-1); math.Abs(float64(box.Velocity.X)-want) > 0.01*want {
+		t.Errorf("velocity %v, expected %v", box.Velocity.X, want)
+	}
+	if want := 0.1 * math.Exp(-0.5); math.Abs(float64(box.AngularVelocity)-want) > 0.01*want {
+		t.Errorf("angular velocity %v, expected %v", box.AngularVelocity, want)
+	}
+}
+
+func TestGravityScale(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	normal := must(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	floating := must(CreateBodyCircle(NewVector(200, 100), 10, 1, false))
+	floating.GravityScale = 0
+	heavy := must(CreateBodyCircle(NewVector(300, 100), 10, 1, false))
+	heavy.GravityScale = 2
+	ignoring := must(CreateBodyCircle(NewVector(400, 100), 10, 1, false))
+	ignoring.UseGravity = false
+
+	stepFrames(10)
+	if normal.Velocity.Y <= 0 {
+		t.Fatalf("velocity %v, expected the body to fall", normal.Velocity)
+	}
+	if floating.Velocity != VectorZero() || ignoring.Velocity != VectorZero() {
+		t.Errorf("velocities %v and %v without gravity, expected 0", floating.Velocity, ignoring.Velocity)
+	}
+	if !nearlyEqual(heavy.Velocity.Y, 2*normal.Velocity.Y) {
+		t.Errorf("velocity %v with twice the gravity, expected %v", heavy.Velocity.Y, 2*normal.Velocity.Y)
+	}
+}
+
+func TestMaxSpeed(t *testing.T) {
+	resetWorld(t)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+	box.MaxLinearSpeed = 0.05
+	box.MaxAngularSpeed = 0.02
+	box.Velocity = NewVector(3, 4)
+	box.AngularVelocity = -1
+
+	UpdatePhysics(1.0 / 60)
+	if speed := VectorLen(box.Velocity); !nearlyEqual(speed, box.MaxLinearSpeed) {
+		t.Errorf("speed %v, expected it clamped to %v", speed, box.MaxLinearSpeed)
+	}
+	if box.AngularVelocity != -box.MaxAngularSpeed {
+		t.Errorf("angular velocity %v, expected it clamped to %v", box.AngularVelocity, -box.MaxAngularSpeed)
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	resetWorld(t)
+	SetFixedTimestep(1.0/60, 4)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(100, 100), 20, 20, 1, false))
+	box.UseGravity = false
+	box.Velocity = NewVector(0.02, 0)
+	box.AngularVelocity = 0.01
+
+	// half a step doesn't simulate anything yet
+	UpdatePhysics(1.0 / 120)
+	if box.GetPos() != NewVector(100, 100) || box.GetInterpolatedPos() != box.GetPos() {
+		t.Fatalf("position %v, interpolated %v before the first step", box.GetPos(), box.GetInterpolatedPos())
+	}
+
+	UpdatePhysics(1.0 / 60)
+	prev, pos := box.prevPosition, box.position
+	if prev == pos {
+		t.Fatal("no step was simulated")
+	}
+	alpha := GetInterpolationAlpha()
+	if !nearlyEqual(alpha, 0.5) {
+		t.Errorf("alpha %v, expected half a step left", alpha)
+	}
+	want := VectorMul(VectorLerp(prev, pos, alpha), ppu)
+	if got := box.GetInterpolatedPos(); !nearlyEqual(got.X, want.X) || !nearlyEqual(got.Y, want.Y) {
+		t.Errorf("interpolated position %v, expected %v", got, want)
+	}
+	if got, want := box.GetInterpolatedRotation(), box.Rotation*alpha; !nearlyEqual(got, want) {
+		t.Errorf("interpolated rotation %v, expected %v", got, want)
+	}
+
+	// teleporting skips the interpolation
+	for _, move := range []func(){
+		func() { box.MoveTo(NewVector(300, 300)) },
+		func() { box.Move(NewVector(50, 0)) },
+	} {
+		move()
+		if box.GetInterpolatedPos() != box.GetPos() {
+			t.Errorf("interpolated position %v after a move to %v", box.GetInterpolatedPos(), box.GetPos())
+		}
+	}
+}
+
+// a long frame runs at most maxSteps steps and drops the rest of its time
+func TestMaxStepsPerFrame(t *testing.T) {
+	resetWorld(t)
+	SetFixedTimestep(1.0/60, 4)
+	mustBody(t)(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+
+	UpdatePhysics(1)
+	if steps := GetStats().Steps; steps != 4*iterations {
+		t.Errorf("%d sub-steps, expected %d", steps, 4*iterations)
+	}
+	if alpha := GetInterpolationAlpha(); alpha < 0 || alpha >= 1 {
+		t.Errorf("alpha %v, expected the leftover time dropped below a step", alpha)
+	}
+
+	UpdatePhysics(1.0 / 60)
+	if steps := GetStats().Steps; steps != iterations {
+		t.Errorf("%d sub-steps on the next frame, expected %d", steps, iterations)
+	}
+}
+
+func TestCreateBodyErrors(t *testing.T) {
+	nan, inf := float32(math.NaN()), float32(math.Inf(1))
+	pos := NewVector(100, 100)
+	tests := []struct {
+		name   string
+		create func() (*Body, error)
+	}{
+		{"zero density", func() (*Body, error) { return CreateBodyCircle(pos, 10, 0, false) }},
+		{"negative density", func() (*Body, error) { return CreateBodyRectangle(pos, 10, 10, -1, false) }},
+		{"NaN density", func() (*Body, error) { return CreateBodyCircle(pos, 10, nan, false) }},
+		{"zero radius", func() (*Body, error) { return CreateBodyCircle(pos, 0, 1, false) }},
+		{"negative radius", func() (*Body, error) { return CreateBodyCircle(pos, -5, 1, true) }},
+		{"Inf radius", func() (*Body, error) { return CreateBodyCircle(pos, inf, 1, true) }},
+		{"zero width", func() (*Body, error) { return CreateBodyRectangle(pos, 0, 10, 1, false) }},
+		{"negative height", func() (*Body, error) { return CreateBodyRectangle(pos, 10, -10, 1, true) }},
+		{"NaN height", func() (*Body, error) { return CreateKinematicRectangle(pos, 10, nan, 1) }},
+		{"NaN position", func() (*Body, error) { return CreateBodyCircle(NewVector(nan, 0), 10, 1, false) }},
+		{"Inf position", func() (*Body, error) { return CreateBodyRectangle(NewVector(0, inf), 10, 10, 1, true) }},
+		{"-Inf position", func() (*Body, error) { return CreateKinematicCircle(NewVector(-inf, 0), 10, 1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetWorld(t)
+			if b, err := tt.create(); err == nil || b != nil {
+				t.Errorf("created %v, expected an error", b)
+			}
+			if GetBodiesCount() != 0 {
+				t.Errorf("%d bodies in the world after a failed create", GetBodiesCount())
+			}
+		})
+	}
+
+	// static and kinematic bodies don't need a density
+	resetWorld(t)
+	mustBody(t)(CreateBodyCircle(pos, 10, 0, true))
+	mustBody(t)(CreateKinematicRectangle(pos, 10, 10, 0))
+}
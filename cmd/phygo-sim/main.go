@@ -0,0 +1,309 @@
+// Command phygo-sim runs a scene file without a window and writes the
+// trajectories of its bodies, the final state and the step statistics.
+//
+//	phygo-sim -scene level.json -steps 600 -out trajectory.csv -final final.json -stats stats.json
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+type options struct {
+	scene  string
+	steps  int
+	dt     float64
+	every  int
+	out    string
+	format string
+	final  string
+	stats  string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.scene, "scene", "", "scene file written by phygo.SaveScene (required)")
+	flag.IntVar(&opts.steps, "steps", 600, "number of UpdatePhysics calls")
+	flag.Float64Var(&opts.dt, "dt", 1.0/60, "time passed to each UpdatePhysics call")
+	flag.IntVar(&opts.every, "every", 1, "write the trajectories every n steps")
+	flag.StringVar(&opts.out, "out", "-", "trajectory file, - for stdout and empty to skip")
+	flag.StringVar(&opts.format, "format", "", "trajectory format, csv or jsonl (default from the -out extension, else csv)")
+	flag.StringVar(&opts.final, "final", "", "file to save the final state to as a scene")
+	flag.StringVar(&opts.stats, "stats", "", "file to write the run statistics to as JSON, - for stderr")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "phygo-sim:", err)
+		os.Exit(1)
+	}
+}
+
+// one line of the trajectory, in the units of the public API
+type sample struct {
+	Step            int     `json:"step"`
+	Time            float64 `json:"time"`
+	Id              int     `json:"id"`
+	X               float32 `json:"x"`
+	Y               float32 `json:"y"`
+	Rotation        float32 `json:"rotation"`
+	VelocityX       float32 `json:"vx"`
+	VelocityY       float32 `json:"vy"`
+	AngularVelocity float32 `json:"angularVelocity"`
+	Awake           bool    `json:"awake"`
+}
+
+var csvHeader = []string{"step", "time", "id", "x", "y", "rotation", "vx", "vy", "angularVelocity", "awake"}
+
+func (s sample) record() []string {
+	f := func(v float32) string {
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	}
+	return []string{
+		strconv.Itoa(s.Step), strconv.FormatFloat(s.Time, 'g', -1, 64), strconv.Itoa(s.Id),
+		f(s.X), f(s.Y), f(s.Rotation), f(s.VelocityX), f(s.VelocityY), f(s.AngularVelocity),
+		strconv.FormatBool(s.Awake),
+	}
+}
+
+type trajectoryWriter interface {
+	write(s sample) error
+	flush() error
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(s sample) error {
+	return c.w.Write(s.record())
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) write(s sample) error {
+	return j.enc.Encode(s)
+}
+
+func (j *jsonlWriter) flush() error {
+	return j.w.Flush()
+}
+
+func newTrajectoryWriter(w io.Writer, format string) (trajectoryWriter, error) {
+	switch format {
+	case "csv":
+		c := csv.NewWriter(w)
+		if err := c.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{c}, nil
+	case "jsonl":
+		b := bufio.NewWriter(w)
+		return &jsonlWriter{b, json.NewEncoder(b)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, expected csv or jsonl", format)
+}
+
+// totals of the run, durations in milliseconds
+type runStats struct {
+	Steps      int     `json:"steps"`
+	Dt         float64 `json:"dt"`
+	WallTimeMs float64 `json:"wallTimeMs"`
+
+	TotalMs       float64 `json:"totalMs"`
+	IntegrationMs float64 `json:"integrationMs"`
+	BroadphaseMs  float64 `json:"broadphaseMs"`
+	NarrowphaseMs float64 `json:"narrowphaseMs"`
+	SolverMs      float64 `json:"solverMs"`
+	IslandsMs     float64 `json:"islandsMs"`
+
+	CandidatePairs int `json:"candidatePairs"`
+	AABBRejects    int `json:"aabbRejects"`
+	SATTests       int `json:"satTests"`
+	Manifolds      int `json:"manifolds"`
+	// contacts ignored because a sub-step found more than the world holds
+	DroppedContacts int `json:"droppedContacts"`
+
+	Bodies      int `json:"bodies"`
+	AwakeBodies int `json:"awakeBodies"`
+	Joints      int `json:"joints"`
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func (r *runStats) add(s phygo.Stats) {
+	r.TotalMs += ms(s.Total)
+	r.IntegrationMs += ms(s.Integration)
+	r.BroadphaseMs += ms(s.Broadphase)
+	r.NarrowphaseMs += ms(s.Narrowphase)
+	r.SolverMs += ms(s.Solver)
+	r.IslandsMs += ms(s.Islands)
+	r.CandidatePairs += s.CandidatePairs
+	r.AABBRejects += s.AABBRejects
+	r.SATTests += s.SATTests
+	r.Manifolds += s.Manifolds
+	r.DroppedContacts += s.DroppedContacts
+	r.Bodies = s.Bodies
+	r.AwakeBodies = s.AwakeBodies
+	r.Joints = s.Joints
+}
+
+func run(opts options) (err error) {
+	if opts.scene == "" {
+		return fmt.Errorf("-scene is required")
+	}
+	if opts.steps < 0 || opts.every < 1 || !(opts.dt > 0) {
+		return fmt.Errorf("-steps can't be negative, -every and -dt must be positive")
+	}
+	if opts.format == "" {
+		opts.format = "csv"
+		if ext := strings.ToLower(filepath.Ext(opts.out)); ext == ".jsonl" || ext == ".ndjson" {
+			opts.format = "jsonl"
+		}
+	}
+
+	if err := loadScene(opts.scene); err != nil {
+		return err
+	}
+	defer phygo.Close()
+
+	var traj trajectoryWriter
+	if opts.out != "" {
+		var out io.Writer
+		var closeOut func() error
+		out, closeOut, err = create(opts.out, os.Stdout)
+		if err != nil {
+			return err
+		}
+		// a failed close can lose the last buffered samples
+		defer func() {
+			if closeErr := closeOut(); err == nil {
+				err = closeErr
+			}
+		}()
+		if traj, err = newTrajectoryWriter(out, opts.format); err != nil {
+			return err
+		}
+	}
+
+	total := runStats{Steps: opts.steps, Dt: opts.dt}
+	start := time.Now()
+	for step := 0; step <= opts.steps; step++ {
+		if step > 0 {
+			phygo.UpdatePhysics(float32(opts.dt))
+			total.add(phygo.GetStats())
+		}
+		if traj != nil && step%opts.every == 0 {
+			if err := writeSamples(traj, step, float64(step)*opts.dt); err != nil {
+				return err
+			}
+		}
+	}
+	total.WallTimeMs = ms(time.Since(start))
+
+	if traj != nil {
+		if err := traj.flush(); err != nil {
+			return err
+		}
+	}
+	if opts.final != "" {
+		if err := saveScene(opts.final); err != nil {
+			return err
+		}
+	}
+	if opts.stats != "" {
+		if err := writeStats(opts.stats, total); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadScene(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return phygo.LoadScene(f)
+}
+
+func saveScene(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := phygo.SaveScene(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeStats(path string, s runStats) error {
+	out, closeOut, err := create(path, os.Stderr)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		closeOut()
+		return err
+	}
+	return closeOut()
+}
+
+// creates the file, or returns std when the path is -
+func create(path string, std *os.File) (io.Writer, func() error, error) {
+	if path == "-" {
+		return std, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+func writeSamples(w trajectoryWriter, step int, t float64) error {
+	for _, b := range phygo.GetBodies() {
+		pos := b.GetPos()
+		err := w.write(sample{
+			Step:            step,
+			Time:            t,
+			Id:              b.Id,
+			X:               pos.X,
+			Y:               pos.Y,
+			Rotation:        b.Rotation,
+			VelocityX:       b.Velocity.X,
+			VelocityY:       b.Velocity.Y,
+			AngularVelocity: b.AngularVelocity,
+			Awake:           b.IsAwake(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
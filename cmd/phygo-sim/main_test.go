@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+// writes a scene with a ground and two falling bodies
+func writeScene(t *testing.T) string {
+	t.Helper()
+	phygo.Close()
+	t.Cleanup(phygo.Close)
+	if _, err := phygo.CreateBodyRectangle(phygo.NewVector(300, 400), 600, 40, 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := phygo.CreateBodyRectangle(phygo.NewVector(200, 300), 20, 20, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := phygo.CreateBodyCircle(phygo.NewVector(400, 300), 10, 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "scene.json")
+	if err := saveScene(path); err != nil {
+		t.Fatal(err)
+	}
+	phygo.Close()
+	return path
+}
+
+func TestRunCSV(t *testing.T) {
+	dir := t.TempDir()
+	opts := options{
+		scene: writeScene(t),
+		steps: 9,
+		dt:    1.0 / 60,
+		every: 3,
+		out:   filepath.Join(dir, "trajectory.csv"),
+		final: filepath.Join(dir, "final.json"),
+		stats: filepath.Join(dir, "stats.json"),
+	}
+	if err := run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(opts.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(records[0], csvHeader) {
+		t.Errorf("header %v, expected %v", records[0], csvHeader)
+	}
+	// steps 0, 3, 6 and 9 for 3 bodies
+	rows := records[1:]
+	if len(rows) != 12 {
+		t.Fatalf("%d rows, expected 12", len(rows))
+	}
+	for i, row := range rows {
+		if want := strconv.Itoa(i / 3 * 3); row[0] != want {
+			t.Errorf("row %d is at step %s, expected %s", i, row[0], want)
+		}
+	}
+
+	// the final scene reloads with the last sampled state
+	if err := loadScene(opts.final); err != nil {
+		t.Fatal(err)
+	}
+	bodies := phygo.GetBodies()
+	if len(bodies) != 3 {
+		t.Fatalf("%d bodies in the final scene, expected 3", len(bodies))
+	}
+	for i, b := range bodies {
+		pos := b.GetPos()
+		want := sample{
+			Step:            9,
+			Time:            9 * opts.dt,
+			Id:              b.Id,
+			X:               pos.X,
+			Y:               pos.Y,
+			Rotation:        b.Rotation,
+			VelocityX:       b.Velocity.X,
+			VelocityY:       b.Velocity.Y,
+			AngularVelocity: b.AngularVelocity,
+			Awake:           b.IsAwake(),
+		}.record()
+		if !slices.Equal(rows[9+i], want) {
+			t.Errorf("body %d reloaded as %v, expected the last row %v", b.Id, want, rows[9+i])
+		}
+	}
+
+	var s runStats
+	data, err := os.ReadFile(opts.stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Steps != 9 || s.Bodies != 3 {
+		t.Errorf("stats of %d steps and %d bodies, expected 9 and 3", s.Steps, s.Bodies)
+	}
+}
+
+func TestRunJSONL(t *testing.T) {
+	opts := options{
+		scene: writeScene(t),
+		steps: 10,
+		dt:    1.0 / 60,
+		every: 5,
+		out:   filepath.Join(t.TempDir(), "trajectory.jsonl"),
+	}
+	if err := run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(opts.out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var steps []int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		steps = append(steps, s.Step)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 0, 0, 5, 5, 5, 10, 10, 10}; !slices.Equal(steps, want) {
+		t.Errorf("samples at steps %v, expected %v", steps, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	scene := writeScene(t)
+	for _, opts := range []options{
+		{steps: 1, dt: 1.0 / 60, every: 1},
+		{scene: filepath.Join(t.TempDir(), "missing.json"), steps: 1, dt: 1.0 / 60, every: 1},
+		{scene: scene, steps: 1, dt: 1.0 / 60, every: 0},
+		{scene: scene, steps: 1, dt: 0, every: 1},
+		{scene: scene, steps: 1, dt: 1.0 / 60, every: 1, out: filepath.Join(t.TempDir(), "out.txt"), format: "xml"},
+	} {
+		if err := run(opts); err == nil {
+			t.Errorf("ran with %+v, expected an error", opts)
+		}
+	}
+}
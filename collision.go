@@ -189,7 +189,7 @@ func findClosestPoint(point Vector, vertices []Vector) int {
 	return result
 }
 
-func findContactPoints(bodyA, bodyB Body) ([2]Vector, int) {
+func findContactPoints(bodyA, bodyB *Body) ([2]Vector, int) {
 	var contactPoints [2]Vector
 	var contactCount int = 0
 
@@ -0,0 +1,131 @@
+package phygo
+
+import "image/color"
+
+// DebugDraw is implemented by renderers to draw the world with DrawDebug.
+// Everything is given in pixels.
+type DebugDraw interface {
+	DrawPolygon(vertices []Vector, color color.RGBA)
+	DrawCircle(center Vector, radius float32, color color.RGBA)
+	DrawSegment(a, b Vector, color color.RGBA)
+	DrawPoint(p Vector, size float32, color color.RGBA)
+	// draws the axes of a body's local frame
+	DrawTransform(pos Vector, rotation float32)
+}
+
+type DebugDrawFlags int
+
+const (
+	// body outlines, colored by type and sleep state
+	DrawShapes DebugDrawFlags = 1 << iota
+	DrawAABBs
+	// contact points and normals of the last sub-step
+	DrawContacts
+	DrawJoints
+	DrawTransforms
+
+	DrawAll = DrawShapes | DrawAABBs | DrawContacts | DrawJoints | DrawTransforms
+)
+
+var (
+	staticColor    = color.RGBA{127, 230, 127, 255}
+	kinematicColor = color.RGBA{127, 127, 230, 255}
+	dynamicColor   = color.RGBA{230, 178, 178, 255}
+	sleepingColor  = color.RGBA{153, 153, 153, 255}
+	aabbColor      = color.RGBA{230, 77, 230, 255}
+	contactColor   = color.RGBA{230, 230, 77, 255}
+	normalColor    = color.RGBA{230, 128, 0, 255}
+	jointColor     = color.RGBA{128, 204, 204, 255}
+)
+
+// length of the drawn contact normals in pixels
+const debugNormalLength = 15
+
+func bodyDebugColor(b *Body) color.RGBA {
+	switch {
+	case b.IsStatic():
+		return staticColor
+	case b.IsKinematic():
+		return kinematicColor
+	case b.isSleeping():
+		return sleepingColor
+	}
+	return dynamicColor
+}
+
+// Draws the world with d, the flags select what is drawn
+func DrawDebug(d DebugDraw, flags DebugDrawFlags) {
+	for _, b := range bodies[:bodyCount] {
+		if flags&DrawShapes != 0 {
+			c := bodyDebugColor(b)
+			if b.ShapeType == CircleShape {
+				center := b.GetPos()
+				d.DrawCircle(center, b.GetRadius(), c)
+				// a radius shows the rotation
+				edge := VectorAdd(center, rotateVector(NewVector(b.GetRadius(), 0), b.Rotation))
+				d.DrawSegment(center, edge, c)
+			} else {
+				vertices := b.GetVertices()
+				d.DrawPolygon(vertices[:], c)
+			}
+		}
+
+		if flags&DrawAABBs != 0 {
+			// drawn as segments so renderers filling polygons don't hide the shapes
+			aabb := b.GetAABB()
+			corners := [4]Vector{
+				aabb.Min,
+				NewVector(aabb.Max.X, aabb.Min.Y),
+				aabb.Max,
+				NewVector(aabb.Min.X, aabb.Max.Y),
+			}
+			for i, c := range corners {
+				d.DrawSegment(c, corners[(i+1)%4], aabbColor)
+			}
+		}
+
+		if flags&DrawTransforms != 0 {
+			d.DrawTransform(b.GetPos(), b.Rotation)
+		}
+	}
+
+	if flags&DrawJoints != 0 {
+		for _, j := range joints[:jointCount] {
+			drawJoint(d, j)
+		}
+	}
+
+	if flags&DrawContacts != 0 {
+		for i := range manifolds[:manifoldCount] {
+			m := &manifolds[i]
+			for _, contact := range m.Contacts[:m.ContactCount] {
+				p := VectorMul(contact, ppu)
+				d.DrawPoint(p, 4, contactColor)
+				d.DrawSegment(p, VectorAdd(p, VectorMul(m.Normal, debugNormalLength)), normalColor)
+			}
+		}
+	}
+}
+
+func drawJoint(d DebugDraw, j Joint) {
+	a, b := j.GetAnchorA(), j.GetAnchorB()
+
+	switch j := j.(type) {
+	case *PulleyJoint:
+		groundA, groundB := j.GetGroundAnchorA(), j.GetGroundAnchorB()
+		d.DrawSegment(groundA, a, jointColor)
+		d.DrawSegment(groundB, b, jointColor)
+		d.DrawSegment(groundA, groundB, jointColor)
+	case *MouseJoint:
+		d.DrawPoint(a, 4, jointColor)
+		d.DrawSegment(a, b, jointColor)
+	default:
+		// from the bodies to their anchors
+		if bodyA := j.GetBodyA(); bodyA != nil {
+			d.DrawSegment(bodyA.GetPos(), a, jointColor)
+		}
+		d.DrawSegment(a, b, jointColor)
+		d.DrawSegment(j.GetBodyB().GetPos(), b, jointColor)
+	}
+	d.DrawPoint(b, 4, jointColor)
+}
@@ -0,0 +1,361 @@
+package phygo
+
+import (
+	"fmt"
+	"math"
+)
+
+var deltaMagic = [4]byte{'P', 'H', 'Y', 'D'}
+
+// DeltaTolerance sets how much a body can change before DiffSnapshots includes it
+type DeltaTolerance struct {
+	Position        float32 // pixels
+	Rotation        float32 // radians
+	Velocity        float32
+	AngularVelocity float32
+}
+
+// SnapshotDelta holds the changes between a baseline snapshot and a newer one,
+// for server authoritative replication. Bodies are matched by id.
+type SnapshotDelta struct {
+	world worldState
+	// bodies created or changed beyond the tolerance
+	bodies []Body
+	// ids of the removed bodies
+	removed []int
+	// ids of every body in the target's order, nil when it follows from the baseline
+	order []int
+	// encoded joints of the target, nil when they didn't change
+	joints []byte
+
+	posStep, velStep float32
+}
+
+// Returns the number of created or changed bodies in the delta
+func (d *SnapshotDelta) GetChangedCount() int {
+	return len(d.bodies)
+}
+
+func (d *SnapshotDelta) GetRemovedIds() []int {
+	return d.removed
+}
+
+func findBodyState(states []Body, id int) int {
+	for i := range states {
+		if states[i].Id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func outside(a, b, tolerance float32) bool {
+	return float32(math.Abs(float64(a-b))) > tolerance
+}
+
+// compares the simulated state within the tolerance and everything else exactly
+func bodyChanged(a, b *Body, tol DeltaTolerance) bool {
+	if outside(a.position.X*ppu, b.position.X*ppu, tol.Position) ||
+		outside(a.position.Y*ppu, b.position.Y*ppu, tol.Position) ||
+		outside(a.Rotation, b.Rotation, tol.Rotation) ||
+		outside(a.Velocity.X, b.Velocity.X, tol.Velocity) ||
+		outside(a.Velocity.Y, b.Velocity.Y, tol.Velocity) ||
+		outside(a.AngularVelocity, b.AngularVelocity, tol.AngularVelocity) {
+		return true
+	}
+
+	// state that is derived or only lives during a step
+	c := *a
+	c.position, c.Rotation = b.position, b.Rotation
+	c.Velocity, c.AngularVelocity = b.Velocity, b.AngularVelocity
+	c.Force, c.Torque = b.Force, b.Torque
+	c.vertices, c.transformUpdateRequired = b.vertices, b.transformUpdateRequired
+	c.aabb, c.aabbUpdateRequired = b.aabb, b.aabbUpdateRequired
+	c.prevPosition, c.prevRotation = b.prevPosition, b.prevRotation
+	c.IsOnGround, c.sleepTime, c.islandIndex = b.IsOnGround, b.sleepTime, b.islandIndex
+	return c != *b
+}
+
+// only the configuration is compared, the accumulated impulses change every
+// step and are sent along when something else did
+func jointsChanged(base, target *Snapshot) bool {
+	if len(base.jointStates) != len(target.jointStates) {
+		return true
+	}
+	for i, a := range base.jointStates {
+		b := target.jointStates[i]
+		if a.GetType() != b.GetType() ||
+			hashBodyId(0, a.GetBodyA()) != hashBodyId(0, b.GetBodyA()) ||
+			hashBodyId(0, a.GetBodyB()) != hashBodyId(0, b.GetBodyB()) ||
+			a.collideConnected() != b.collideConnected() ||
+			jointConfigChanged(a, b, base.joints, target.joints) {
+			return true
+		}
+	}
+	return false
+}
+
+// a and b have the same type, gear joints are compared by the index of the joints they couple
+func jointConfigChanged(a, b Joint, baseJoints, targetJoints []Joint) bool {
+	switch a := a.(type) {
+	case *MouseJoint:
+		b := b.(*MouseJoint)
+		return a.localAnchor != b.localAnchor || a.target != b.target || a.MaxForce != b.MaxForce ||
+			a.Frequency != b.Frequency || a.DampingRatio != b.DampingRatio
+	case *RevoluteJoint:
+		b := b.(*RevoluteJoint)
+		return a.localAnchorA != b.localAnchorA || a.localAnchorB != b.localAnchorB || a.referenceAngle != b.referenceAngle ||
+			a.EnableMotor != b.EnableMotor || a.MotorSpeed != b.MotorSpeed || a.MaxMotorTorque != b.MaxMotorTorque
+	case *PrismaticJoint:
+		b := b.(*PrismaticJoint)
+		return a.localAnchorA != b.localAnchorA || a.localAnchorB != b.localAnchorB || a.localAxisA != b.localAxisA ||
+			a.referenceAngle != b.referenceAngle ||
+			a.EnableMotor != b.EnableMotor || a.MotorSpeed != b.MotorSpeed || a.MaxMotorForce != b.MaxMotorForce
+	case *WheelJoint:
+		b := b.(*WheelJoint)
+		return a.localAnchorA != b.localAnchorA || a.localAnchorB != b.localAnchorB || a.localAxisA != b.localAxisA ||
+			a.Frequency != b.Frequency || a.DampingRatio != b.DampingRatio ||
+			a.EnableMotor != b.EnableMotor || a.MotorSpeed != b.MotorSpeed || a.MaxMotorTorque != b.MaxMotorTorque
+	case *PulleyJoint:
+		b := b.(*PulleyJoint)
+		return a.groundAnchorA != b.groundAnchorA || a.groundAnchorB != b.groundAnchorB ||
+			a.localAnchorA != b.localAnchorA || a.localAnchorB != b.localAnchorB ||
+			a.constant != b.constant || a.ratio != b.ratio
+	case *GearJoint:
+		b := b.(*GearJoint)
+		return jointIndexIn(a.joint1, baseJoints) != jointIndexIn(b.joint1, targetJoints) ||
+			jointIndexIn(a.joint2, baseJoints) != jointIndexIn(b.joint2, targetJoints) ||
+			a.constant != b.constant || a.ratio != b.ratio
+	}
+	return true
+}
+
+// Computes the changes from base to target into dst and returns it,
+// a new delta is created when dst is nil. The target's quantization is used when encoding.
+func DiffSnapshots(base, target *Snapshot, tol DeltaTolerance, dst *SnapshotDelta) *SnapshotDelta {
+	return diffSnapshots(base, target, func(a, b *Body) bool { return bodyChanged(a, b, tol) }, dst)
+}
+
+func diffSnapshots(base, target *Snapshot, changed func(a, b *Body) bool, dst *SnapshotDelta) *SnapshotDelta {
+	if dst == nil {
+		dst = &SnapshotDelta{}
+	}
+	d := dst
+	d.world = target.world
+	d.posStep, d.velStep = target.posStep, target.velStep
+	d.bodies = d.bodies[:0]
+	d.removed = d.removed[:0]
+	d.order = d.order[:0]
+	d.joints = nil
+
+	for i := range target.bodyStates {
+		b := &target.bodyStates[i]
+		if index := findBodyState(base.bodyStates, b.Id); index == -1 || changed(&base.bodyStates[index], b) {
+			d.bodies = append(d.bodies, *b)
+		}
+	}
+	for i := range base.bodyStates {
+		if findBodyState(target.bodyStates, base.bodyStates[i].Id) == -1 {
+			d.removed = append(d.removed, base.bodyStates[i].Id)
+		}
+	}
+
+	// the order is only sent when it differs from the baseline's without the
+	// removed bodies, followed by the created ones
+	inferred := d.order[:0]
+	for i := range base.bodyStates {
+		if id := base.bodyStates[i].Id; findBodyState(target.bodyStates, id) != -1 {
+			inferred = append(inferred, id)
+		}
+	}
+	for i := range target.bodyStates {
+		if id := target.bodyStates[i].Id; findBodyState(base.bodyStates, id) == -1 {
+			inferred = append(inferred, id)
+		}
+	}
+	d.order = inferred[:0]
+	for i := range target.bodyStates {
+		if inferred[i] != target.bodyStates[i].Id {
+			for j := range target.bodyStates {
+				d.order = append(d.order, target.bodyStates[j].Id)
+			}
+			break
+		}
+	}
+
+	if jointsChanged(base, target) {
+		w := binWriter{jointBodies: target.bodies}
+		w.u32(uint32(len(target.jointStates)))
+		for _, j := range target.jointStates {
+			w.joint(j, target.joints)
+		}
+		d.joints = w.buf
+	}
+	return d
+}
+
+// Applies the delta to the baseline it was computed from, writing the result
+// into dst (a new snapshot when nil) which can then be restored. dst must not be base.
+// Bodies are bound to the baseline's bodies, or to the current world's with the same id.
+func ApplySnapshotDelta(base *Snapshot, d *SnapshotDelta, dst *Snapshot) (*Snapshot, error) {
+	if dst == nil {
+		dst = &Snapshot{}
+	}
+	dst.world = d.world
+	dst.posStep, dst.velStep = d.posStep, d.velStep
+	dst.manifolds = dst.manifolds[:0]
+	dst.bodies = dst.bodies[:0]
+	dst.bodyStates = dst.bodyStates[:0]
+
+	addState := func(id int) {
+		var state *Body
+		var ptr *Body
+		if i := findBodyState(d.bodies, id); i != -1 {
+			state = &d.bodies[i]
+		}
+		if i := findBodyState(base.bodyStates, id); i != -1 {
+			ptr = base.bodies[i]
+			if state == nil {
+				state = &base.bodyStates[i]
+			}
+		}
+		if ptr == nil {
+			if ptr = bodyById(id); ptr == nil {
+				ptr = &Body{}
+			}
+		}
+		dst.bodies = append(dst.bodies, ptr)
+		dst.bodyStates = append(dst.bodyStates, *state)
+	}
+
+	if len(d.order) > 0 {
+		for _, id := range d.order {
+			if findBodyState(d.bodies, id) == -1 && findBodyState(base.bodyStates, id) == -1 {
+				return nil, fmt.Errorf("phygo: delta refers to body %d missing from the baseline", id)
+			}
+			addState(id)
+		}
+	} else {
+		for i := range base.bodyStates {
+			if id := base.bodyStates[i].Id; !containsId(d.removed, id) {
+				addState(id)
+			}
+		}
+		for i := range d.bodies {
+			if findBodyState(base.bodyStates, d.bodies[i].Id) == -1 {
+				addState(d.bodies[i].Id)
+			}
+		}
+	}
+	if len(dst.bodies) > maxBodies {
+		return nil, fmt.Errorf("phygo: delta results in %d bodies, at most %d are supported", len(dst.bodies), maxBodies)
+	}
+
+	if d.joints == nil {
+		dst.joints = append(dst.joints[:0], base.joints...)
+		for i, j := range base.jointStates {
+			if i < len(dst.jointStates) {
+				dst.jointStates[i] = j.copyInto(dst.jointStates[i])
+			} else {
+				dst.jointStates = append(dst.jointStates, j.copyInto(nil))
+			}
+		}
+		dst.jointStates = dst.jointStates[:len(base.jointStates)]
+		return dst, nil
+	}
+
+	r := binReader{buf: d.joints}
+	count := int(r.u32())
+	if r.err == nil && count > maxJoints {
+		return nil, fmt.Errorf("phygo: delta has %d joints, at most %d are supported", count, maxJoints)
+	}
+	dst.joints = dst.joints[:0]
+	dst.jointStates = dst.jointStates[:0]
+	for i := 0; i < count; i++ {
+		j, err := r.joint(dst.bodies, dst.joints)
+		if err != nil {
+			return nil, err
+		}
+		dst.jointStates = append(dst.jointStates, j)
+		if i < len(base.joints) && sameJoint(base.jointStates[i], j) {
+			dst.joints = append(dst.joints, base.joints[i])
+		} else {
+			dst.joints = append(dst.joints, j.copyInto(nil))
+		}
+	}
+	return dst, r.err
+}
+
+func containsId(ids []int, id int) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *SnapshotDelta) MarshalBinary() ([]byte, error) {
+	w := binWriter{posStep: d.posStep, velStep: d.velStep}
+	w.header(deltaMagic)
+	w.world(d.world)
+
+	w.u32(uint32(len(d.bodies)))
+	for i := range d.bodies {
+		w.body(&d.bodies[i])
+	}
+	w.u32(uint32(len(d.removed)))
+	for _, id := range d.removed {
+		w.i32(int32(id))
+	}
+	w.u32(uint32(len(d.order)))
+	for _, id := range d.order {
+		w.i32(int32(id))
+	}
+
+	w.bool(d.joints != nil)
+	if d.joints != nil {
+		w.u32(uint32(len(d.joints)))
+		w.buf = append(w.buf, d.joints...)
+	}
+	return w.buf, nil
+}
+
+func (d *SnapshotDelta) UnmarshalBinary(data []byte) error {
+	r := binReader{buf: data}
+	if err := r.header(deltaMagic); err != nil {
+		return err
+	}
+
+	decoded := SnapshotDelta{posStep: r.posStep, velStep: r.velStep}
+	decoded.world = r.world()
+
+	count := int(r.u32())
+	if r.err == nil && count > maxBodies {
+		return fmt.Errorf("phygo: delta has %d bodies, at most %d are supported", count, maxBodies)
+	}
+	decoded.bodies = make([]Body, count)
+	for i := range decoded.bodies {
+		if err := r.body(&decoded.bodies[i]); err != nil {
+			return err
+		}
+	}
+	for _, ids := range []*[]int{&decoded.removed, &decoded.order} {
+		count = int(r.u32())
+		if r.err == nil && count > maxBodies {
+			return fmt.Errorf("phygo: delta refers to %d bodies, at most %d are supported", count, maxBodies)
+		}
+		for i := 0; i < count && r.err == nil; i++ {
+			*ids = append(*ids, int(r.i32()))
+		}
+	}
+
+	if r.bool() {
+		decoded.joints = append([]byte{}, r.next(int(r.u32()))...)
+	}
+	if r.err != nil {
+		return r.err
+	}
+	*d = decoded
+	return nil
+}
@@ -0,0 +1,158 @@
+package phygo
+
+import (
+	"slices"
+	"testing"
+)
+
+// applies the delta to base and checks it gives the bodies of target in its order
+func checkDelta(t *testing.T, base, target *Snapshot, d *SnapshotDelta) {
+	t.Helper()
+	got, err := ApplySnapshotDelta(base, d, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.bodyStates) != len(target.bodyStates) {
+		t.Fatalf("%d bodies, expected %d", len(got.bodyStates), len(target.bodyStates))
+	}
+	for i := range target.bodyStates {
+		a, b := &got.bodyStates[i], &target.bodyStates[i]
+		if a.Id != b.Id || a.StateHash() != b.StateHash() {
+			t.Errorf("body %d at index %d, expected body %d", a.Id, i, b.Id)
+		}
+	}
+	if len(got.jointStates) != len(target.jointStates) {
+		t.Errorf("%d joints, expected %d", len(got.jointStates), len(target.jointStates))
+	}
+}
+
+func TestDeltaUnchanged(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	runHashes(10)
+	base := TakeSnapshot(nil)
+	d := DiffSnapshots(base, TakeSnapshot(nil), DeltaTolerance{}, nil)
+	if d.GetChangedCount() != 0 || len(d.GetRemovedIds()) != 0 || len(d.order) != 0 || d.joints != nil {
+		t.Errorf("delta of an unchanged world has %d bodies, %v removed, order %v and %d bytes of joints",
+			d.GetChangedCount(), d.GetRemovedIds(), d.order, len(d.joints))
+	}
+	checkDelta(t, base, base, d)
+}
+
+func TestDeltaCreatedAndRemoved(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	base := TakeSnapshot(nil)
+
+	created := mustBody(t)(CreateBodyCircle(NewVector(700, 100), 10, 1, false))
+	removed := GetBodies()[3]
+	RemoveBody(removed)
+	target := TakeSnapshot(nil)
+
+	d := DiffSnapshots(base, target, DeltaTolerance{}, nil)
+	if d.GetChangedCount() != 1 || d.bodies[0].Id != created.Id {
+		t.Errorf("%d changed bodies, expected only the created body %d", d.GetChangedCount(), created.Id)
+	}
+	if ids := d.GetRemovedIds(); !slices.Equal(ids, []int{removed.Id}) {
+		t.Errorf("removed %v, expected %d", ids, removed.Id)
+	}
+	if len(d.order) != 0 {
+		t.Errorf("order %v sent, expected it inferred from the baseline", d.order)
+	}
+	checkDelta(t, base, target, d)
+}
+
+func TestDeltaReordered(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	base := TakeSnapshot(nil)
+	target := TakeSnapshot(nil)
+	target.bodies[1], target.bodies[2] = target.bodies[2], target.bodies[1]
+	target.bodyStates[1], target.bodyStates[2] = target.bodyStates[2], target.bodyStates[1]
+
+	d := DiffSnapshots(base, target, DeltaTolerance{}, nil)
+	if d.GetChangedCount() != 0 || len(d.order) != len(target.bodyStates) {
+		t.Errorf("%d changed bodies and order %v, expected only the order", d.GetChangedCount(), d.order)
+	}
+	checkDelta(t, base, target, d)
+}
+
+func TestDeltaTolerance(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	base := TakeSnapshot(nil)
+	GetBodies()[1].Move(NewVector(0.5, 0))
+	target := TakeSnapshot(nil)
+
+	if d := DiffSnapshots(base, target, DeltaTolerance{Position: 1}, nil); d.GetChangedCount() != 0 {
+		t.Errorf("%d changed bodies, expected the move within the tolerance skipped", d.GetChangedCount())
+	}
+	if d := DiffSnapshots(base, target, DeltaTolerance{Position: 0.1}, nil); d.GetChangedCount() != 1 {
+		t.Errorf("%d changed bodies, expected the moved body", d.GetChangedCount())
+	}
+}
+
+// stepping changes the joint impulses, which aren't a configuration change
+func TestDeltaJoints(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	joint := GetJoints()[0].(*RevoluteJoint)
+	joint.EnableMotor = true
+	joint.MaxMotorTorque = 100
+	runHashes(10)
+	base := TakeSnapshot(nil)
+	impulse := joint.motorImpulse
+	runHashes(10)
+	if joint.motorImpulse == impulse {
+		t.Fatal("the motor impulse didn't change")
+	}
+
+	if d := DiffSnapshots(base, TakeSnapshot(nil), DeltaTolerance{}, nil); d.joints != nil {
+		t.Error("joints sent after stepping, expected only configuration changes to send them")
+	}
+
+	joint.MotorSpeed = 0.1
+	target := TakeSnapshot(nil)
+	d := DiffSnapshots(base, target, DeltaTolerance{}, nil)
+	if d.joints == nil {
+		t.Fatal("joints not sent after changing the motor speed")
+	}
+	got, err := ApplySnapshotDelta(base, d, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j := got.jointStates[0].(*RevoluteJoint); j.MotorSpeed != 0.1 || j.motorImpulse != joint.motorImpulse {
+		t.Errorf("motor speed %v and impulse %v, expected 0.1 and %v", j.MotorSpeed, j.motorImpulse, joint.motorImpulse)
+	}
+}
+
+func TestDeltaBinaryRoundTrip(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	base := TakeSnapshot(nil)
+	runHashes(20)
+	mustBody(t)(CreateBodyCircle(NewVector(700, 100), 10, 1, false))
+	RemoveBody(GetBodies()[3])
+	GetJoints()[0].(*RevoluteJoint).EnableMotor = true
+	target := TakeSnapshot(nil)
+
+	d := DiffSnapshots(base, target, DeltaTolerance{}, nil)
+	data, err := d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded SnapshotDelta
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.GetChangedCount() != d.GetChangedCount() || !slices.Equal(decoded.GetRemovedIds(), d.GetRemovedIds()) ||
+		(decoded.joints == nil) != (d.joints == nil) {
+		t.Errorf("decoded delta with %d bodies and %v removed, expected %d and %v",
+			decoded.GetChangedCount(), decoded.GetRemovedIds(), d.GetChangedCount(), d.GetRemovedIds())
+	}
+	checkDelta(t, base, target, &decoded)
+
+	if err := decoded.UnmarshalBinary(data[:len(data)-3]); err == nil {
+		t.Error("decoded a truncated delta")
+	}
+}
@@ -0,0 +1,75 @@
+package phygo
+
+import "math"
+
+var deterministic = false
+
+// Enables the deterministic mode, where identical inputs produce bit-identical
+// results on every platform, for lockstep and rollback netcode.
+//
+// The guarantee holds when every machine creates, removes and mutates bodies
+// and joints in the same order with the same values:
+//   - bodies and joints are always processed in creation order, RemoveBody and
+//     RemoveJoint keep the order of the remaining ones
+//   - products are explicitly rounded so no platform fuses them into FMA instructions
+//   - trigonometry uses the portable sinCos instead of math.Sin and math.Cos,
+//     which have assembly implementations on some architectures
+//
+// The remaining math (sqrt, floor, mod, abs) is exact under IEEE 754.
+func SetDeterministic(enabled bool) {
+	deterministic = enabled
+	for _, b := range bodies[:bodyCount] {
+		b.transformUpdateRequired = true
+		b.aabbUpdateRequired = true
+	}
+}
+
+func IsDeterministic() bool {
+	return deterministic
+}
+
+// pi/2 split in two parts for an exact range reduction
+const (
+	pio2Hi = 1.5707963267341256e+00
+	pio2Lo = 6.077100506506192e-11
+)
+
+// Returns the sine and cosine of an angle using only basic arithmetic,
+// every operation is rounded explicitly so the result is the same everywhere
+func sinCos(angle float32) (float32, float32) {
+	x := float64(angle)
+
+	// reducing the angle to [-pi/4, pi/4] and its quadrant
+	k := math.Floor(float64(x*(2/math.Pi)) + 0.5)
+	r := x - float64(k*pio2Hi) - float64(k*pio2Lo)
+	z := float64(r * r)
+
+	// taylor series, accurate far beyond float32 precision on the reduced range
+	s := 1.0 / 6227020800
+	s = float64(s*z) - 1.0/39916800
+	s = float64(s*z) + 1.0/362880
+	s = float64(s*z) - 1.0/5040
+	s = float64(s*z) + 1.0/120
+	s = float64(s*z) - 1.0/6
+	s = float64(s*z) + 1
+	s = float64(s * r)
+
+	c := 1.0 / 87178291200
+	c = float64(c*z) - 1.0/479001600
+	c = float64(c*z) + 1.0/3628800
+	c = float64(c*z) - 1.0/40320
+	c = float64(c*z) + 1.0/720
+	c = float64(c*z) - 1.0/24
+	c = float64(c*z) + 1.0/2
+	c = 1 - float64(c*z)
+
+	switch int64(k) & 3 {
+	case 1:
+		s, c = c, -s
+	case 2:
+		s, c = -s, -c
+	case 3:
+		s, c = -c, s
+	}
+	return float32(s), float32(c)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"log"
+
 	"github.com/ab-dek/Phygo-2D/phygo"
 
 	rl "github.com/gen2brain/raylib-go/raylib"
@@ -19,20 +21,20 @@ func main() {
 	rl.SetTargetFPS(60)
 
 	// Create Rectange Body
-	player := phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, 0), 45, 45, 1, false)
+	player := must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, 0), 45, 45, 1, false))
 	player.RotationDisabled = true
 	player.SetDynamicFriction(0.8)
 	
 	// ground body(Static)
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, float32(screenHeight)-25), float32(screenWidth), 50, 1, true)
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, float32(screenHeight)-25), float32(screenWidth), 50, 1, true))
 	
 	// walls(Static)
-	phygo.CreateBodyRectangle(phygo.NewVector(0, float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true)
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth), float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true)
+	must(phygo.CreateBodyRectangle(phygo.NewVector(0, float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true))
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth), float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true))
 	
 	// platforms(Static)
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth*2/3), float32(screenHeight/3)), float32(screenWidth)/3, 10, 1, true)
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth/3), float32(screenHeight*2/3)), float32(screenWidth)/3, 10, 1, true)
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth*2/3), float32(screenHeight/3)), float32(screenWidth)/3, 10, 1, true))
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth/3), float32(screenHeight*2/3)), float32(screenWidth)/3, 10, 1, true))
 	
 	for !rl.WindowShouldClose() {
 		phygo.UpdatePhysics(rl.GetFrameTime())
@@ -66,3 +68,11 @@ func main() {
 		rl.EndDrawing()
 	}
 }
+
+// exits when a body can't be created
+func must(b *phygo.Body, err error) *phygo.Body {
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
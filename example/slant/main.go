@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"math"
 	"math/rand"
 	
@@ -23,28 +24,28 @@ func main() {
 	for i := 0; i < 30; i++ {
 		if i % 2 == 0 {
 			// Create rectangle bodies
-			r := phygo.CreateBodyRectangle(phygo.NewVector(rand.Float32()*float32(screenWidth), -rand.Float32()*float32(screenHeight-50)), 30, 30, 1, false)
+			r := must(phygo.CreateBodyRectangle(phygo.NewVector(rand.Float32()*float32(screenWidth), -rand.Float32()*float32(screenHeight-50)), 30, 30, 1, false))
 			r.SetRestitution(0.3)
 			r.SetDynamicFriction(0.1)
 		} else {
 			// Create circle bodies
-			c := phygo.CreateBodyCircle(phygo.NewVector(rand.Float32()*float32(screenWidth), -rand.Float32()*float32(screenHeight-50)), float32(rand.Intn(20)+10), 1, false)
+			c := must(phygo.CreateBodyCircle(phygo.NewVector(rand.Float32()*float32(screenWidth), -rand.Float32()*float32(screenHeight-50)), float32(rand.Intn(20)+10), 1, false))
 			c.SetRestitution(0.7)
 			c.SetDynamicFriction(0.1)
 		}
 	}
 	
 	// ground body
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, float32(screenHeight)-25), float32(screenWidth), 50, 1, true)
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth)/2, float32(screenHeight)-25), float32(screenWidth), 50, 1, true))
 
 	// walls
-	phygo.CreateBodyRectangle(phygo.NewVector(0, float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true)
-	phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth), float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true)
+	must(phygo.CreateBodyRectangle(phygo.NewVector(0, float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true))
+	must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth), float32(screenHeight/2)), 20, float32(screenHeight)-100, 1, true))
 
 	// slants
-	slant1 := phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth*2/3), float32(screenHeight/3)), 10, float32(screenHeight)-150, 1, true)
+	slant1 := must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth*2/3), float32(screenHeight/3)), 10, float32(screenHeight)-150, 1, true))
 	slant1.RotateTo(60*math.Pi/180)
-	slant2 := phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth/3), float32(screenHeight*2/3)), 10, float32(screenHeight)-150, 1, true)
+	slant2 := must(phygo.CreateBodyRectangle(phygo.NewVector(float32(screenWidth/3), float32(screenHeight*2/3)), 10, float32(screenHeight)-150, 1, true))
 	slant2.RotateTo(-70*math.Pi/180)
 
 	for !rl.WindowShouldClose() {
@@ -70,3 +71,11 @@ func main() {
 		rl.EndDrawing()
 	}
 }
+
+// exits when a body can't be created
+func must(b *phygo.Body, err error) *phygo.Body {
+	if err != nil {
+		log.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,167 @@
+package phygo
+
+import "errors"
+
+// GearJoint couples two revolute or prismatic joints so that
+// coordinate1 + Ratio * coordinate2 stays constant, where a coordinate is the
+// joint angle of a revolute joint or the translation of a prismatic joint.
+type GearJoint struct {
+	jointBase
+	joint1, joint2 Joint
+	// the bodies the coupled joints are attached to, bodyA and bodyB are the moving ones
+	bodyC, bodyD *Body
+	constant     float32
+	ratio        float32
+
+	jvAC, jvBD         Vector
+	jwA, jwB, jwC, jwD float32
+	rA, rB, rC, rD     Vector
+	mass               float32
+	bias               float32
+}
+
+// joint1 and joint2 must be revolute or prismatic joints
+func CreateGearJoint(joint1, joint2 Joint, ratio float32) (*GearJoint, error) {
+	if !isGearCompatible(joint1) || !isGearCompatible(joint2) {
+		return nil, errors.New("phygo: gear joints couple revolute or prismatic joints")
+	}
+	if joint1 == joint2 {
+		return nil, errors.New("phygo: gear joints couple two different joints")
+	}
+	if err := checkJointCount(); err != nil {
+		return nil, err
+	}
+
+	newJoint := &GearJoint{
+		jointBase: jointBase{bodyA: joint1.GetBodyB(), bodyB: joint2.GetBodyB(), CollideConnected: true},
+		joint1:    joint1,
+		joint2:    joint2,
+		bodyC:     joint1.GetBodyA(),
+		bodyD:     joint2.GetBodyA(),
+		ratio:     ratio,
+	}
+	newJoint.constant = gearCoordinate(joint1) + float32(ratio*gearCoordinate(joint2))
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func isGearCompatible(j Joint) bool {
+	switch j.(type) {
+	case *RevoluteJoint, *PrismaticJoint:
+		return true
+	}
+	return false
+}
+
+func gearCoordinate(j Joint) float32 {
+	switch j := j.(type) {
+	case *RevoluteJoint:
+		return j.GetJointAngle()
+	case *PrismaticJoint:
+		return j.translation()
+	}
+	return 0
+}
+
+func (j *GearJoint) GetType() JointType {
+	return GearJointType
+}
+
+func (j *GearJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*GearJoint)
+	if !ok {
+		d = &GearJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *GearJoint) hashState(h uint64) uint64 {
+	h = hashFloat(h, j.constant)
+	return hashFloat(h, j.ratio)
+}
+
+// Returns the center of body A, gears have no anchors
+func (j *GearJoint) GetAnchorA() Vector {
+	return j.bodyA.GetPos()
+}
+
+func (j *GearJoint) GetAnchorB() Vector {
+	return j.bodyB.GetPos()
+}
+
+func (j *GearJoint) GetJoint1() Joint {
+	return j.joint1
+}
+
+func (j *GearJoint) GetJoint2() Joint {
+	return j.joint2
+}
+
+func (j *GearJoint) GetRatio() float32 {
+	return j.ratio
+}
+
+func (j *GearJoint) prepare(dt float32) {
+	bodyA, bodyB, bodyC, bodyD := j.bodyA, j.bodyB, j.bodyC, j.bodyD
+	h := dt * ppu
+
+	mA, mB, mC, mD := bodyA.invMass, bodyB.invMass, bodyC.invMass, bodyD.invMass
+	iA, iB, iC, iD := bodyA.getInvInertia(), bodyB.getInvInertia(), bodyC.getInvInertia(), bodyD.getInvInertia()
+	j.mass = 0
+
+	switch j1 := j.joint1.(type) {
+	case *RevoluteJoint:
+		j.jvAC = VectorZero()
+		j.jwA = 1
+		j.jwC = 1
+		j.mass += iA + iC
+	case *PrismaticJoint:
+		u := rotateVector(j1.localAxisA, bodyC.Rotation)
+		j.rC = rotateVector(j1.localAnchorA, bodyC.Rotation)
+		j.rA = rotateVector(j1.localAnchorB, bodyA.Rotation)
+		j.jvAC = u
+		j.jwC = VectorCrossProduct(j.rC, u)
+		j.jwA = VectorCrossProduct(j.rA, u)
+		j.mass += mC + mA + float32(iC*j.jwC*j.jwC) + float32(iA*j.jwA*j.jwA)
+	}
+
+	switch j2 := j.joint2.(type) {
+	case *RevoluteJoint:
+		j.jvBD = VectorZero()
+		j.jwB = j.ratio
+		j.jwD = j.ratio
+		j.mass += float32(j.ratio * j.ratio * (iB + iD))
+	case *PrismaticJoint:
+		u := rotateVector(j2.localAxisA, bodyD.Rotation)
+		j.rD = rotateVector(j2.localAnchorA, bodyD.Rotation)
+		j.rB = rotateVector(j2.localAnchorB, bodyB.Rotation)
+		j.jvBD = VectorMul(u, j.ratio)
+		j.jwD = j.ratio * VectorCrossProduct(j.rD, u)
+		j.jwB = j.ratio * VectorCrossProduct(j.rB, u)
+		j.mass += float32(j.ratio*j.ratio*(mD+mB)) + float32(iD*j.jwD*j.jwD) + float32(iB*j.jwB*j.jwB)
+	}
+
+	if j.mass > 0 {
+		j.mass = 1 / j.mass
+	}
+
+	c := gearCoordinate(j.joint1) + float32(j.ratio*gearCoordinate(j.joint2)) - j.constant
+	j.bias = c * jointBaumgarte / h
+}
+
+func (j *GearJoint) solve(dt float32) {
+	bodyA, bodyB, bodyC, bodyD := j.bodyA, j.bodyB, j.bodyC, j.bodyD
+
+	cdot := VectorDotProduct(j.jvAC, VectorSubtract(bodyA.Velocity, bodyC.Velocity)) +
+		VectorDotProduct(j.jvBD, VectorSubtract(bodyB.Velocity, bodyD.Velocity)) +
+		(float32(j.jwA*bodyA.AngularVelocity) - float32(j.jwC*bodyC.AngularVelocity)) +
+		(float32(j.jwB*bodyB.AngularVelocity) - float32(j.jwD*bodyD.AngularVelocity))
+	impulse := -j.mass * (cdot + j.bias)
+
+	applyJointImpulse(bodyA, VectorMul(j.jvAC, impulse), impulse*j.jwA)
+	applyJointImpulse(bodyB, VectorMul(j.jvBD, impulse), impulse*j.jwB)
+	applyJointImpulse(bodyC, VectorMul(j.jvAC, -impulse), -impulse*j.jwC)
+	applyJointImpulse(bodyD, VectorMul(j.jvBD, -impulse), -impulse*j.jwD)
+}
@@ -0,0 +1,184 @@
+package phygo
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+const (
+	goldenDt    = 1.0 / 60
+	goldenEvery = 10
+
+	// allowed difference from the golden trajectories
+	positionTolerance = 0.5  // pixels
+	rotationTolerance = 0.01 // radians
+)
+
+type goldenFile struct {
+	Steps  int           `json:"steps"`
+	Every  int           `json:"every"`
+	Frames []goldenFrame `json:"frames"`
+}
+
+type goldenFrame struct {
+	Step   int          `json:"step"`
+	Bodies []goldenBody `json:"bodies"`
+}
+
+type goldenBody struct {
+	Id       int     `json:"id"`
+	X        float32 `json:"x"`
+	Y        float32 `json:"y"`
+	Rotation float32 `json:"rotation"`
+}
+
+type scenario struct {
+	name  string
+	steps int
+	setup func(t *testing.T)
+}
+
+var scenarios = []scenario{
+	{"box_stack", 300, setupBoxStack},
+	{"pyramid", 300, setupPyramid},
+	{"pendulum", 300, setupPendulum},
+	{"bouncing_ball", 300, setupBouncingBall},
+	{"slope", 300, setupSlope},
+}
+
+func createGround(t *testing.T) *Body {
+	return mustBody(t)(CreateBodyRectangle(NewVector(400, 500), 800, 40, 1, true))
+}
+
+func setupBoxStack(t *testing.T) {
+	must := mustBody(t)
+	createGround(t)
+	for i := 0; i < 3; i++ {
+		must(CreateBodyRectangle(NewVector(400, 459-float32(i)*41), 40, 40, 1, false))
+	}
+}
+
+func setupPyramid(t *testing.T) {
+	must := mustBody(t)
+	createGround(t)
+	const rows = 4
+	for row := 0; row < rows; row++ {
+		for i := 0; i < rows-row; i++ {
+			x := 400 + (float32(i)-float32(rows-row-1)/2)*42
+			must(CreateBodyRectangle(NewVector(x, 459-float32(row)*41), 40, 40, 1, false))
+		}
+	}
+}
+
+func setupPendulum(t *testing.T) {
+	must := mustBody(t)
+	anchor := must(CreateBodyCircle(NewVector(400, 100), 5, 1, true))
+	bob := must(CreateBodyCircle(NewVector(550, 100), 15, 1, false))
+	_, err := CreateRevoluteJoint(anchor, bob, NewVector(400, 100))
+	noError(t, err)
+}
+
+func setupBouncingBall(t *testing.T) {
+	ground := createGround(t)
+	ground.SetRestitution(0.8)
+	ball := mustBody(t)(CreateBodyCircle(NewVector(400, 100), 20, 1, false))
+	ball.SetRestitution(0.8)
+}
+
+// a box sliding down a slope as in example/slant
+func setupSlope(t *testing.T) {
+	must := mustBody(t)
+	createGround(t)
+	slope := must(CreateBodyRectangle(NewVector(300, 300), 400, 10, 1, true))
+	slope.RotateTo(30 * math.Pi / 180)
+	box := must(CreateBodyRectangle(NewVector(200, 220), 30, 30, 1, false))
+	box.RotateTo(30 * math.Pi / 180)
+	box.SetDynamicFriction(0.1)
+}
+
+func recordScenario(t *testing.T, s scenario) goldenFile {
+	resetWorld(t)
+	SetDeterministic(true)
+	s.setup(t)
+
+	g := goldenFile{Steps: s.steps, Every: goldenEvery}
+	for step := 0; step <= s.steps; step++ {
+		if step > 0 {
+			UpdatePhysics(goldenDt)
+		}
+		if step%goldenEvery != 0 {
+			continue
+		}
+		frame := goldenFrame{Step: step}
+		for _, b := range GetBodies() {
+			pos := b.GetPos()
+			frame.Bodies = append(frame.Bodies, goldenBody{b.Id, pos.X, pos.Y, b.Rotation})
+		}
+		g.Frames = append(g.Frames, frame)
+	}
+	return g
+}
+
+func compareGolden(want, got goldenFile) error {
+	if len(want.Frames) != len(got.Frames) {
+		return fmt.Errorf("%d frames, expected %d", len(got.Frames), len(want.Frames))
+	}
+	for i, wf := range want.Frames {
+		gf := got.Frames[i]
+		if len(wf.Bodies) != len(gf.Bodies) {
+			return fmt.Errorf("step %d: %d bodies, expected %d", wf.Step, len(gf.Bodies), len(wf.Bodies))
+		}
+		for j, wb := range wf.Bodies {
+			gb := gf.Bodies[j]
+			if gb.Id != wb.Id ||
+				math.Abs(float64(gb.X-wb.X)) > positionTolerance ||
+				math.Abs(float64(gb.Y-wb.Y)) > positionTolerance ||
+				math.Abs(float64(gb.Rotation-wb.Rotation)) > rotationTolerance {
+				return fmt.Errorf("step %d: body %+v, expected %+v", wf.Step, gb, wb)
+			}
+		}
+	}
+	return nil
+}
+
+func TestGoldenTrajectories(t *testing.T) {
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			got := recordScenario(t, s)
+			path := filepath.Join("testdata", "golden", s.name+".json")
+
+			if *update {
+				data, err := json.MarshalIndent(got, "", "\t")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			var want goldenFile
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := compareGolden(want, got); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
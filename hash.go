@@ -0,0 +1,80 @@
+package phygo
+
+import "math"
+
+// 64 bit FNV-1a
+const (
+	hashOffset uint64 = 14695981039346656037
+	hashPrime  uint64 = 1099511628211
+)
+
+type BodyHash struct {
+	Id   int
+	Hash uint64
+}
+
+func hashUint32(h uint64, v uint32) uint64 {
+	for i := 0; i < 4; i++ {
+		h ^= uint64(byte(v >> (8 * i)))
+		h *= hashPrime
+	}
+	return h
+}
+
+func hashFloat(h uint64, f float32) uint64 {
+	return hashUint32(h, math.Float32bits(f))
+}
+
+func hashVector(h uint64, v Vector) uint64 {
+	return hashFloat(hashFloat(h, v.X), v.Y)
+}
+
+func hashBool(h uint64, b bool) uint64 {
+	if b {
+		return hashUint32(h, 1)
+	}
+	return hashUint32(h, 0)
+}
+
+func hashBodyId(h uint64, b *Body) uint64 {
+	if b == nil {
+		return hashUint32(h, math.MaxUint32)
+	}
+	return hashUint32(h, uint32(b.Id))
+}
+
+// Returns a stable hash of the body's position, rotation and velocities,
+// equal on every machine running the same deterministic simulation
+func (b *Body) StateHash() uint64 {
+	h := hashUint32(hashOffset, uint32(b.Id))
+	h = hashVector(h, b.position)
+	h = hashFloat(h, b.Rotation)
+	h = hashVector(h, b.Velocity)
+	h = hashFloat(h, b.AngularVelocity)
+	return hashBool(h, b.awake)
+}
+
+// Returns a stable hash of every body and joint, comparing it between
+// clients each tick detects desyncs
+func StateHash() uint64 {
+	h := hashOffset
+	for _, b := range bodies[:bodyCount] {
+		h ^= b.StateHash()
+		h *= hashPrime
+	}
+	for _, j := range joints[:jointCount] {
+		h = hashUint32(h, uint32(j.GetType()))
+		h = hashBodyId(h, j.GetBodyA())
+		h = hashBodyId(h, j.GetBodyB())
+		h = j.hashState(h)
+	}
+	return h
+}
+
+// Appends the hash of every body to dst, to find which body diverged first
+func StateHashPerBody(dst []BodyHash) []BodyHash {
+	for _, b := range bodies[:bodyCount] {
+		dst = append(dst, BodyHash{b.Id, b.StateHash()})
+	}
+	return dst
+}
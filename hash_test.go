@@ -0,0 +1,106 @@
+package phygo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const determinismSteps = 3000
+
+func TestDeterministicRuns(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	first := runHashes(determinismSteps)
+
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	compareHashes(t, first, runHashes(determinismSteps))
+}
+
+// the deterministic run ends in the checked in hash, which catches changes
+// that are reproducible but different from the previous versions
+func TestGoldenStateHash(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	hashes := runHashes(determinismSteps)
+	got := fmt.Sprintf("%016x", hashes[len(hashes)-1])
+	path := filepath.Join("testdata", "golden", "state_hash.txt")
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if want := strings.TrimSpace(string(data)); got != want {
+		t.Errorf("hash %s after %d steps, expected %s", got, determinismSteps, want)
+	}
+}
+
+// removing a body keeps the order of the others, which the hashes and the
+// pair iteration depend on
+func TestRemoveBodyKeepsOrder(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	var want []int
+	for _, b := range GetBodies() {
+		if b.Id != 3 {
+			want = append(want, b.Id)
+		}
+	}
+
+	_, removed := GetBody(3)
+	RemoveBody(removed)
+	for i, b := range GetBodies() {
+		if b.Id != want[i] {
+			t.Fatalf("body %d at index %d, expected %d", b.Id, i, want[i])
+		}
+	}
+}
+
+// restoring a snapshot and stepping again gives the same states
+func TestSnapshotRollback(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	runHashes(100)
+
+	s := TakeSnapshot(nil)
+	want := runHashes(500)
+	RestoreSnapshot(s)
+	compareHashes(t, want, runHashes(500))
+}
+
+// changing one body changes only its own entry
+func TestStateHashPerBody(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	runHashes(10)
+	before := StateHashPerBody(nil)
+	if len(before) != len(GetBodies()) {
+		t.Fatalf("%d hashes for %d bodies", len(before), len(GetBodies()))
+	}
+
+	changed := GetBodies()[2]
+	changed.Velocity.X += 0.01
+	after := StateHashPerBody(nil)
+	for i := range before {
+		if after[i].Id != before[i].Id {
+			t.Fatalf("entry %d is body %d, expected %d", i, after[i].Id, before[i].Id)
+		}
+		if differs := after[i].Hash != before[i].Hash; differs != (before[i].Id == changed.Id) {
+			t.Errorf("body %d hash changed: %v", before[i].Id, differs)
+		}
+	}
+}
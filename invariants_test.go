@@ -0,0 +1,103 @@
+package phygo
+
+import (
+	"math"
+	"testing"
+)
+
+func momentum(bs ...*Body) Vector {
+	var p Vector
+	for _, b := range bs {
+		p = VectorAdd(p, VectorMul(b.Velocity, b.mass))
+	}
+	return p
+}
+
+func kineticEnergy(bs ...*Body) float32 {
+	var e float32
+	for _, b := range bs {
+		e += b.mass*VectorLenSqr(b.Velocity)/2 + b.inertia*b.AngularVelocity*b.AngularVelocity/2
+	}
+	return e
+}
+
+// an elastic head-on collision without gravity keeps the momentum and the kinetic energy
+func TestMomentumConservation(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	must := mustBody(t)
+	a := must(CreateBodyCircle(NewVector(200, 300), 20, 1, false))
+	b := must(CreateBodyCircle(NewVector(400, 300), 30, 1, false))
+	for _, body := range []*Body{a, b} {
+		body.SetRestitution(1)
+		body.SetStaticFriction(0)
+		body.SetDynamicFriction(0)
+	}
+	a.Velocity = NewVector(2, 0)
+	b.Velocity = NewVector(-1, 0)
+
+	p0, e0 := momentum(a, b), kineticEnergy(a, b)
+	collided := false
+	for step := 0; step < 300; step++ {
+		UpdatePhysics(1.0 / 60)
+		if p := momentum(a, b); VectorLen(VectorSubtract(p, p0)) > 1e-3*VectorLen(p0) {
+			t.Fatalf("step %d: momentum %v, expected %v", step, p, p0)
+		}
+		if a.Velocity.X < 0 {
+			collided = true
+		}
+	}
+	if !collided {
+		t.Fatal("the bodies never collided")
+	}
+	if e := kineticEnergy(a, b); math.Abs(float64(e-e0)) > 0.05*float64(e0) {
+		t.Errorf("kinetic energy %v, expected %v", e, e0)
+	}
+}
+
+// a fast small body is stopped by a thin wall
+func TestNoTunneling(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	must := mustBody(t)
+	wall := must(CreateBodyRectangle(NewVector(500, 300), 10, 200, 1, true))
+	ball := must(CreateBodyCircle(NewVector(100, 300), 5, 1, false))
+	ball.Velocity = NewVector(20, 0)
+
+	for step := 0; step < 120; step++ {
+		UpdatePhysics(1.0 / 60)
+		if ball.GetPos().X > wall.GetPos().X {
+			t.Fatalf("step %d: the ball went through the wall at %v", step, ball.GetPos())
+		}
+	}
+	if ball.Velocity.X > 0 {
+		t.Errorf("the ball never hit the wall, it is at %v", ball.GetPos())
+	}
+}
+
+// nothing falls through the ground and the stacks come to rest
+func TestRestingContacts(t *testing.T) {
+	for _, s := range []scenario{scenarios[0], scenarios[1]} {
+		t.Run(s.name, func(t *testing.T) {
+			resetWorld(t)
+			s.setup(t)
+			_, ground := GetBody(0)
+			top := ground.GetAABB().Min.Y
+
+			for step := 0; step < 600; step++ {
+				UpdatePhysics(1.0 / 60)
+				for _, b := range GetBodies()[1:] {
+					if bottom := b.GetAABB().Max.Y; bottom > top+1 {
+						t.Fatalf("step %d: body %d sinks %v pixels into the ground", step, b.Id, bottom-top)
+					}
+				}
+			}
+			// gravity leaves a small velocity on resting bodies
+			for _, b := range GetBodies()[1:] {
+				if speed := VectorLen(b.Velocity); speed > 0.02 {
+					t.Errorf("body %d still moves at %v", b.Id, speed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package phygo
+
+import "math"
+
+// bodies resting slower than these thresholds for timeToSleep seconds fall asleep,
+// sleeping is off until enabled with SetSleepEnabled
+const (
+	defaultLinearSleepTolerance  = 0.005
+	defaultAngularSleepTolerance = 0.002
+	defaultTimeToSleep           = 0.5
+)
+
+var (
+	sleepEnabled          = false
+	linearSleepTolerance  = float32(defaultLinearSleepTolerance)
+	angularSleepTolerance = float32(defaultAngularSleepTolerance)
+	timeToSleep           = float32(defaultTimeToSleep)
+
+	onSleep func(b *Body)
+	onWake  func(b *Body)
+)
+
+// union-find storage, indexed by the body's position in the bodies list
+var (
+	islandParent    [maxBodies]int
+	islandSleepTime [maxBodies]float32
+)
+
+// Lets resting islands fall asleep and skip the simulation until something
+// touches them, off by default
+func SetSleepEnabled(enabled bool) {
+	sleepEnabled = enabled
+	if !enabled {
+		for _, b := range bodies[:bodyCount] {
+			b.SetAwake(true)
+		}
+	}
+}
+
+// Sets the velocity thresholds (same units as Velocity and AngularVelocity)
+// below which a body is considered resting, and the time in seconds it has to rest before sleeping
+func SetSleepThresholds(linear, angular, time float32) {
+	linearSleepTolerance = linear
+	angularSleepTolerance = angular
+	timeToSleep = time
+}
+
+// Sets functions called when a body falls asleep or wakes up, nil disables them
+func SetSleepCallbacks(sleep, wake func(b *Body)) {
+	onSleep = sleep
+	onWake = wake
+}
+
+func findIsland(i int) int {
+	for islandParent[i] != i {
+		islandParent[i] = islandParent[islandParent[i]]
+		i = islandParent[i]
+	}
+	return i
+}
+
+func unionIslands(bodyA, bodyB *Body) {
+	// islands don't propagate through static bodies
+	if bodyA == nil || bodyB == nil || bodyA.bodyType == StaticBody || bodyB.bodyType == StaticBody {
+		return
+	}
+	rootA := findIsland(bodyA.islandIndex)
+	rootB := findIsland(bodyB.islandIndex)
+	if rootA != rootB {
+		islandParent[rootB] = rootA
+	}
+}
+
+// builds the islands from the contacts and joints of the last step and puts
+// every island whose bodies have all been resting long enough to sleep
+func updateSleep(time float32) {
+	if !sleepEnabled {
+		return
+	}
+
+	for i, b := range bodies[:bodyCount] {
+		b.islandIndex = i
+		islandParent[i] = i
+		islandSleepTime[i] = float32(math.MaxFloat32)
+
+		if !b.awake {
+			continue
+		}
+		// a moving kinematic body keeps going however slow it is
+		moving := b.bodyType == KinematicBody && (!VectorEquals(b.Velocity, VectorZero()) || b.AngularVelocity != 0)
+		if !b.AllowSleep || moving ||
+			VectorLenSqr(b.Velocity) > linearSleepTolerance*linearSleepTolerance ||
+			b.AngularVelocity*b.AngularVelocity > angularSleepTolerance*angularSleepTolerance {
+			b.sleepTime = 0
+		} else {
+			b.sleepTime += time
+		}
+	}
+
+	for i := range manifolds[:manifoldCount] {
+		unionIslands(manifolds[i].BodyA, manifolds[i].BodyB)
+	}
+	for _, j := range joints[:jointCount] {
+		unionIslands(j.GetBodyA(), j.GetBodyB())
+	}
+
+	// an island sleeps only when its most recently active body does
+	for i, b := range bodies[:bodyCount] {
+		if !b.awake {
+			continue
+		}
+		root := findIsland(i)
+		if b.sleepTime < islandSleepTime[root] {
+			islandSleepTime[root] = b.sleepTime
+		}
+	}
+
+	for i, b := range bodies[:bodyCount] {
+		if b.awake && islandSleepTime[findIsland(i)] >= timeToSleep {
+			b.SetAwake(false)
+		}
+	}
+}
+
+// sleeping bodies that were given a velocity or force directly wake up
+func wakeTouchedBodies() {
+	for _, b := range bodies[:bodyCount] {
+		if b.isSleeping() && (!VectorEquals(b.Velocity, VectorZero()) || b.AngularVelocity != 0 ||
+			!VectorEquals(b.Force, VectorZero()) || b.Torque != 0) {
+			b.SetAwake(true)
+		}
+	}
+}
+
+func jointIsAwake(j Joint) bool {
+	a, b := j.GetBodyA(), j.GetBodyB()
+	return (a != nil && a.awake) || (b != nil && b.awake)
+}
+
+// wakes the bodies of joints that have at least one awake body, returns if the joint needs solving
+func wakeJoint(j Joint) bool {
+	if !jointIsAwake(j) {
+		return false
+	}
+	a, b := j.GetBodyA(), j.GetBodyB()
+	if a != nil && a.isSleeping() {
+		a.SetAwake(true)
+	}
+	if b != nil && b.isSleeping() {
+		b.SetAwake(true)
+	}
+	return true
+}
+
+// wakes the bodies around a removed body so they don't float in place
+func wakeBodiesTouching(b *Body) {
+	for _, other := range bodies[:bodyCount] {
+		if other != b && other.isSleeping() && CheckCollisionAABBs(other.aabb, b.aabb) {
+			other.SetAwake(true)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package phygo
+
+import "errors"
+
+var ErrTooManyJoints = errors.New("phygo: too many joints")
+
+type JointType int
+
+const (
+	MouseJointType JointType = iota
+	RevoluteJointType
+	PrismaticJointType
+	WheelJointType
+	PulleyJointType
+	GearJointType
+)
+
+type Joint interface {
+	GetType() JointType
+	GetBodyA() *Body
+	GetBodyB() *Body
+	// anchor points in pixels, for drawing
+	GetAnchorA() Vector
+	GetAnchorB() Vector
+
+	collideConnected() bool
+	// copies the joint's state into dst when it has the same type, otherwise into a new joint
+	copyInto(dst Joint) Joint
+	hashState(h uint64) uint64
+	prepare(dt float32)
+	solve(dt float32)
+}
+
+// shared by all joints
+type jointBase struct {
+	bodyA, bodyB *Body
+	// whether the connected bodies still collide with each other
+	CollideConnected bool
+}
+
+func (j *jointBase) GetBodyA() *Body {
+	return j.bodyA
+}
+
+func (j *jointBase) GetBodyB() *Body {
+	return j.bodyB
+}
+
+func (j *jointBase) collideConnected() bool {
+	return j.CollideConnected
+}
+
+func GetJoints() []Joint {
+	return joints[:jointCount]
+}
+
+func GetJointsCount() int {
+	return jointCount
+}
+
+// checks the bodies of a new joint and that the world has room for it
+func checkJointInput(bodyA, bodyB *Body) error {
+	if bodyA == nil || bodyB == nil {
+		return errors.New("phygo: joints need two bodies, got nil")
+	}
+	if bodyA == bodyB {
+		return errors.New("phygo: a joint can't connect a body to itself")
+	}
+	return checkJointCount()
+}
+
+func checkJointCount() error {
+	if jointCount >= maxJoints {
+		return ErrTooManyJoints
+	}
+	return nil
+}
+
+// the caller checks that the world has room for the joint
+func addJoint(j Joint) {
+	joints[jointCount] = j
+	jointCount++
+}
+
+func RemoveJoint(j Joint) {
+	index := -1
+	for i := 0; i < jointCount; i++ {
+		if joints[i] == j {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return
+	}
+
+	joints[index] = nil
+
+	for i := index; i+1 < jointCount; i++ {
+		joints[i] = joints[i+1]
+	}
+	jointCount--
+	joints[jointCount] = nil
+
+	// gear joints can't work without the joints they couple
+	for i := jointCount - 1; i >= 0; i-- {
+		if g, ok := joints[i].(*GearJoint); ok && (g.joint1 == j || g.joint2 == j) {
+			RemoveJoint(g)
+		}
+	}
+}
+
+// removes every joint attached to the body
+func removeBodyJoints(b *Body) {
+	for i := jointCount - 1; i >= 0; i-- {
+		if j := joints[i]; j.GetBodyA() == b || j.GetBodyB() == b {
+			RemoveJoint(j)
+		}
+	}
+}
+
+// checks if a joint between the bodies prevents them from colliding
+func jointPreventsCollision(bodyA, bodyB *Body) bool {
+	for _, j := range joints[:jointCount] {
+		if j.collideConnected() {
+			continue
+		}
+		a, b := j.GetBodyA(), j.GetBodyB()
+		if (a == bodyA && b == bodyB) || (a == bodyB && b == bodyA) {
+			return true
+		}
+	}
+	return false
+}
+
+// converts a world point (in units) to the body's local space
+func localPoint(b *Body, p Vector) Vector {
+	return VectorTransform(VectorSubtract(p, b.position), NewTransform(0, 0, -b.Rotation))
+}
+
+// Returns the local point of the body in world pixels
+func worldAnchor(b *Body, local Vector) Vector {
+	return VectorMul(VectorAdd(b.position, rotateVector(local, b.Rotation)), ppu)
+}
+
+func rotateVector(v Vector, angle float32) Vector {
+	return VectorTransform(v, NewTransform(0, 0, angle))
+}
+
+func applyJointImpulse(b *Body, linear Vector, angular float32) {
+	b.Velocity.AddValue(VectorMul(linear, b.invMass))
+	b.AngularVelocity += float32(angular * b.getInvInertia())
+}
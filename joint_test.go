@@ -0,0 +1,263 @@
+package phygo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestJointErrors(t *testing.T) {
+	resetWorld(t)
+	a := mustBody(t)(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	b := mustBody(t)(CreateBodyCircle(NewVector(200, 100), 10, 1, false))
+
+	if _, err := CreateRevoluteJoint(a, nil, NewVector(150, 100)); err == nil {
+		t.Error("created a revolute joint without a second body")
+	}
+	if _, err := CreatePrismaticJoint(a, a, NewVector(150, 100), NewVector(1, 0)); err == nil {
+		t.Error("created a prismatic joint connecting a body to itself")
+	}
+	if _, err := CreateMouseJoint(nil, NewVector(150, 100), 100, 5, 0.7); err == nil {
+		t.Error("created a mouse joint without a body")
+	}
+	wheel, err := CreateWheelJoint(a, b, NewVector(200, 100), NewVector(0, 1), 4, 0.7)
+	noError(t, err)
+	if _, err := CreateGearJoint(wheel, wheel, 1); err == nil {
+		t.Error("created a gear joint coupling wheel joints")
+	}
+	revolute, err := CreateRevoluteJoint(a, b, NewVector(150, 100))
+	noError(t, err)
+	if _, err := CreateGearJoint(revolute, revolute, 1); err == nil {
+		t.Error("created a gear joint coupling a joint with itself")
+	}
+	for _, ratio := range []float32{0, -1} {
+		if _, err := CreatePulleyJoint(a, b, NewVector(100, 0), NewVector(200, 0), NewVector(100, 100), NewVector(200, 100), ratio); err == nil {
+			t.Errorf("created a pulley joint with ratio %v", ratio)
+		}
+	}
+	if GetJointsCount() != 2 {
+		t.Errorf("%d joints, expected only the wheel and revolute joints", GetJointsCount())
+	}
+}
+
+func TestTooManyJoints(t *testing.T) {
+	resetWorld(t)
+	a := mustBody(t)(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	b := mustBody(t)(CreateBodyCircle(NewVector(200, 100), 10, 1, false))
+
+	for i := 0; i < maxJoints; i++ {
+		_, err := CreateRevoluteJoint(a, b, NewVector(150, 100))
+		noError(t, err)
+	}
+	if _, err := CreateRevoluteJoint(a, b, NewVector(150, 100)); !errors.Is(err, ErrTooManyJoints) {
+		t.Errorf("got %v, expected ErrTooManyJoints", err)
+	}
+	if GetJointsCount() != maxJoints {
+		t.Errorf("%d joints, expected %d", GetJointsCount(), maxJoints)
+	}
+}
+
+// the grabbed point is pulled onto the target
+func TestMouseJoint(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(100, 100), 20, 20, 1, false))
+	mouse, err := CreateMouseJoint(box, NewVector(105, 100), 1000, 5, 0.7)
+	noError(t, err)
+	mouse.SetTarget(NewVector(200, 150))
+
+	for step := 0; step < 300; step++ {
+		UpdatePhysics(1.0 / 60)
+	}
+	if d := VectorDistance(mouse.GetAnchor(), mouse.GetTarget()); d > 1 {
+		t.Errorf("anchor %v is %v pixels from the target %v", mouse.GetAnchor(), d, mouse.GetTarget())
+	}
+}
+
+// a frame can't change the velocity by more than MaxForce allows
+func TestMouseJointMaxForce(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	box := mustBody(t)(CreateBodyRectangle(NewVector(100, 100), 20, 20, 1, false))
+	box.RotationDisabled = true
+	const maxForce = 0.01
+	mouse, err := CreateMouseJoint(box, NewVector(100, 100), maxForce, 5, 0.7)
+	noError(t, err)
+	mouse.SetTarget(NewVector(1000, 100))
+
+	const dt = 1.0 / 60
+	UpdatePhysics(dt)
+	limit := maxForce * dt * box.invMass
+	// the target is far enough for the spring to want more
+	if speed := VectorLen(box.Velocity); speed < limit*0.99 || speed > limit*1.001 {
+		t.Errorf("speed %v after a frame, expected the capped %v", speed, limit)
+	}
+}
+
+// bodies grabbed at different points don't hash the same
+func TestMouseJointHash(t *testing.T) {
+	hashGrab := func(grab Vector) uint64 {
+		resetWorld(t)
+		box := mustBody(t)(CreateBodyRectangle(NewVector(100, 100), 20, 20, 1, false))
+		mouse, err := CreateMouseJoint(box, grab, 100, 5, 0.7)
+		noError(t, err)
+		mouse.SetTarget(NewVector(100, 100))
+		return StateHash()
+	}
+	if hashGrab(NewVector(95, 100)) == hashGrab(NewVector(105, 100)) {
+		t.Error("different grab points hash the same")
+	}
+}
+
+func stepFrames(n int) {
+	for i := 0; i < n; i++ {
+		UpdatePhysics(1.0 / 60)
+	}
+}
+
+// a pendulum keeps its anchors together and a motor drives its wheel at the motor speed
+func TestRevoluteJoint(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	ground := must(CreateBodyRectangle(NewVector(400, 500), 800, 40, 1, true))
+	bob := must(CreateBodyCircle(NewVector(550, 100), 15, 1, false))
+	pendulum, err := CreateRevoluteJoint(ground, bob, NewVector(400, 100))
+	noError(t, err)
+
+	wheel := must(CreateBodyCircle(NewVector(100, 100), 20, 1, false))
+	motor, err := CreateRevoluteJoint(ground, wheel, NewVector(100, 100))
+	noError(t, err)
+	motor.EnableMotor = true
+	motor.MotorSpeed = 0.05
+	motor.MaxMotorTorque = 1000
+
+	for step := 0; step < 300; step++ {
+		UpdatePhysics(1.0 / 60)
+		if d := VectorDistance(pendulum.GetAnchorA(), pendulum.GetAnchorB()); d > 1 {
+			t.Fatalf("step %d: the pendulum anchors are %v pixels apart", step, d)
+		}
+	}
+	if VectorLen(bob.Velocity) == 0 {
+		t.Error("the pendulum never swung")
+	}
+	if math.Abs(float64(wheel.AngularVelocity-motor.MotorSpeed)) > 1e-3 {
+		t.Errorf("wheel turns at %v, expected the motor speed %v", wheel.AngularVelocity, motor.MotorSpeed)
+	}
+	if d := VectorDistance(wheel.GetPos(), NewVector(100, 100)); d > 1 {
+		t.Errorf("the wheel moved %v pixels off its axle", d)
+	}
+}
+
+// a box sliding under gravity stays on the diagonal axis without turning
+func TestPrismaticJoint(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	ground := must(CreateBodyRectangle(NewVector(400, 500), 800, 40, 1, true))
+	box := must(CreateBodyRectangle(NewVector(200, 100), 20, 20, 1, false))
+	axis := VectorNormalize(NewVector(1, 1))
+	slider, err := CreatePrismaticJoint(ground, box, NewVector(200, 100), axis)
+	noError(t, err)
+
+	stepFrames(60)
+	offset := VectorSubtract(box.GetPos(), NewVector(200, 100))
+	if along := VectorDotProduct(offset, axis); along < 10 {
+		t.Errorf("the box slid %v pixels, expected it to slide down the axis", along)
+	}
+	if across := VectorCrossProduct(axis, offset); math.Abs(float64(across)) > 0.5 {
+		t.Errorf("the box is %v pixels off the axis", across)
+	}
+	if math.Abs(float64(box.Rotation)) > 1e-3 {
+		t.Errorf("the box turned by %v", box.Rotation)
+	}
+	if d := slider.GetJointTranslation() - VectorDotProduct(offset, axis); math.Abs(float64(d)) > 0.5 {
+		t.Errorf("translation %v, expected %v", slider.GetJointTranslation(), VectorDotProduct(offset, axis))
+	}
+}
+
+// a wheel hanging from its suspension sags and settles on the axis
+func TestWheelJoint(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	car := must(CreateBodyRectangle(NewVector(400, 100), 60, 20, 1, true))
+	wheel := must(CreateBodyCircle(NewVector(400, 130), 10, 1, false))
+	suspension, err := CreateWheelJoint(car, wheel, NewVector(400, 130), NewVector(0, 1), 2, 0.7)
+	noError(t, err)
+
+	stepFrames(300)
+	sag := suspension.GetJointTranslation()
+	stepFrames(60)
+	if sag <= 0 {
+		t.Errorf("suspension travel %v, expected the spring to sag under the wheel", sag)
+	}
+	if d := suspension.GetJointTranslation() - sag; math.Abs(float64(d)) > 0.1 {
+		t.Errorf("the suspension still moves by %v pixels a second", d)
+	}
+	if x := wheel.GetPos().X; math.Abs(float64(x-400)) > 0.5 {
+		t.Errorf("the wheel left the vertical axis, x = %v", x)
+	}
+
+	// a rigid axis doesn't sag
+	resetWorld(t)
+	car = must(CreateBodyRectangle(NewVector(400, 100), 60, 20, 1, true))
+	wheel = must(CreateBodyCircle(NewVector(400, 130), 10, 1, false))
+	rigid, err := CreateWheelJoint(car, wheel, NewVector(400, 130), NewVector(0, 1), 0, 0)
+	noError(t, err)
+	stepFrames(120)
+	if travel := rigid.GetJointTranslation(); math.Abs(float64(travel)) > 0.5 {
+		t.Errorf("rigid suspension travel %v, expected 0", travel)
+	}
+	if travel := rigid.GetJointTranslation(); travel >= sag {
+		t.Errorf("rigid suspension travel %v, expected less than the spring's %v", travel, sag)
+	}
+}
+
+// the rope length lengthA + ratio * lengthB stays constant while the heavier side falls
+func TestPulleyJoint(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	light := must(CreateBodyRectangle(NewVector(100, 300), 20, 20, 1, false))
+	heavy := must(CreateBodyRectangle(NewVector(300, 300), 20, 20, 4, false))
+	const ratio = 2
+	pulley, err := CreatePulleyJoint(light, heavy, NewVector(100, 100), NewVector(300, 100), NewVector(100, 300), NewVector(300, 300), ratio)
+	noError(t, err)
+	length := pulley.GetLengthA() + ratio*pulley.GetLengthB()
+
+	for step := 0; step < 120; step++ {
+		UpdatePhysics(1.0 / 60)
+		if l := pulley.GetLengthA() + ratio*pulley.GetLengthB(); math.Abs(float64(l-length)) > 1 {
+			t.Fatalf("step %d: rope length %v, expected %v", step, l, length)
+		}
+	}
+	if heavy.GetPos().Y <= 300 || light.GetPos().Y >= 300 {
+		t.Errorf("light box at %v, heavy box at %v, expected the heavy one to pull the light one up", light.GetPos(), heavy.GetPos())
+	}
+}
+
+// turning one geared wheel turns the other by the ratio
+func TestGearJoint(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	must := mustBody(t)
+	ground := must(CreateBodyRectangle(NewVector(400, 500), 800, 40, 1, true))
+	wheelA := must(CreateBodyCircle(NewVector(200, 300), 20, 1, false))
+	wheelB := must(CreateBodyCircle(NewVector(300, 300), 20, 1, false))
+	revoluteA, err := CreateRevoluteJoint(ground, wheelA, NewVector(200, 300))
+	noError(t, err)
+	revoluteB, err := CreateRevoluteJoint(ground, wheelB, NewVector(300, 300))
+	noError(t, err)
+	const ratio = 2
+	_, err = CreateGearJoint(revoluteA, revoluteB, ratio)
+	noError(t, err)
+	revoluteA.EnableMotor = true
+	revoluteA.MotorSpeed = 0.02
+	revoluteA.MaxMotorTorque = 1000
+
+	stepFrames(120)
+	angleA, angleB := revoluteA.GetJointAngle(), revoluteB.GetJointAngle()
+	if math.Abs(float64(angleA)) < 0.5 {
+		t.Fatalf("wheel A turned by %v, expected the motor to turn it", angleA)
+	}
+	if c := angleA + ratio*angleB; math.Abs(float64(c)) > 0.01 {
+		t.Errorf("angles %v and %v, expected angleA + %v * angleB to stay 0", angleA, angleB, ratio)
+	}
+}
@@ -10,14 +10,21 @@ type Manifold struct {
 }
 
 func createManifold(bodyA *Body, bodyB *Body, normal Vector, depth float32, contacts [2]Vector, contactCount int) {
-	newManifold := &Manifold{
+	// contacts past the limit can't be stored, they are counted in the stats
+	// and reported by the validation mode
+	if manifoldCount >= maxManifold {
+		stats.DroppedContacts++
+		droppedContacts++
+		return
+	}
+	// manifolds are stored by value and reused every step so stepping doesn't allocate
+	manifolds[manifoldCount] = Manifold{
 		BodyA:        bodyA,
 		BodyB:        bodyB,
 		Normal:       normal,
-		Depth: 		  depth,
+		Depth:        depth,
 		Contacts:     contacts,
 		ContactCount: contactCount,
 	}
-	manifolds[manifoldCount] = newManifold
 	manifoldCount++
-}
\ No newline at end of file
+}
@@ -2,20 +2,23 @@ package phygo
 
 import "math"
 
+// products are converted explicitly to keep the compiler from fusing them
+// into FMA instructions, which would make results differ between platforms
+
 func VectorLen(v Vector) float32 {
-	return float32(math.Sqrt(float64((v.X * v.X) + (v.Y * v.Y))))
+	return float32(math.Sqrt(float64(float32(v.X*v.X) + float32(v.Y*v.Y))))
 }
 
 func VectorDotProduct(v1, v2 Vector) float32 {
-	return v1.X*v2.X + v1.Y*v2.Y
+	return float32(v1.X*v2.X) + float32(v1.Y*v2.Y)
 }
 
 func VectorDistance(v1, v2 Vector) float32 {
-	return float32(math.Sqrt(float64((v1.X-v2.X)*(v1.X-v2.X) + (v1.Y-v2.Y)*(v1.Y-v2.Y))))
+	return float32(math.Sqrt(float64(VectorDistSqr(v1, v2))))
 }
 
 func VectorMul(v Vector, scale float32) Vector {
-	return NewVector(v.X*scale, v.Y*scale)
+	return NewVector(float32(v.X*scale), float32(v.Y*scale))
 }
 
 func VectorNormalize(v Vector) Vector {
@@ -27,20 +30,22 @@ func VectorNormalize(v Vector) Vector {
 
 // Returns the len squared of a vector
 func VectorLenSqr(v Vector) float32 {
-	return v.X*v.X + v.Y*v.Y
+	return float32(v.X*v.X) + float32(v.Y*v.Y)
 }
 
 // Returns the distance squared
 func VectorDistSqr(v1, v2 Vector) float32 {
-	return float32(float64((v1.X-v2.X)*(v1.X-v2.X) + (v1.Y-v2.Y)*(v1.Y-v2.Y)))
+	dx := v1.X - v2.X
+	dy := v1.Y - v2.Y
+	return float32(dx*dx) + float32(dy*dy)
 }
 
 func VectorCrossProduct(v1, v2 Vector) float32 {
-	return v1.X*v2.Y - v1.Y*v2.X
+	return float32(v1.X*v2.Y) - float32(v1.Y*v2.X)
 }
 
 func VectorLerp(v1, v2 Vector, amount float32) Vector {
-	return NewVector(v1.X+amount*(v2.X-v1.X), v1.Y+amount*(v2.Y-v1.Y))
+	return NewVector(v1.X+float32(amount*(v2.X-v1.X)), v1.Y+float32(amount*(v2.Y-v1.Y)))
 }
 
 func ClampFloat(value, min, max float32) float32 {
@@ -81,4 +86,30 @@ func NearlyEqual(a, b float32) bool {
 
 func VectorNearlyEqual(a, b Vector) bool {
 	return VectorDistSqr(a, b) < 0.000001*0.000001
-}
\ No newline at end of file
+}
+
+// Returns the cross product of a scalar and a vector
+func crossSV(s float32, v Vector) Vector {
+	return NewVector(float32(-s*v.Y), float32(s*v.X))
+}
+
+// 2x2 matrix stored as columns
+type mat22 struct {
+	ex, ey Vector
+}
+
+func (m mat22) mulV(v Vector) Vector {
+	return NewVector(float32(m.ex.X*v.X)+float32(m.ey.X*v.Y), float32(m.ex.Y*v.X)+float32(m.ey.Y*v.Y))
+}
+
+func (m mat22) inverse() mat22 {
+	a, b, c, d := m.ex.X, m.ey.X, m.ex.Y, m.ey.Y
+	det := float32(a*d) - float32(b*c)
+	if det != 0 {
+		det = 1 / det
+	}
+	return mat22{
+		ex: NewVector(det*d, -det*c),
+		ey: NewVector(-det*b, det*a),
+	}
+}
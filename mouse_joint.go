@@ -0,0 +1,144 @@
+package phygo
+
+import (
+	"errors"
+	"math"
+)
+
+// MouseJoint pulls a point on a body towards a target with a soft spring,
+// limited by MaxForce. Useful for dragging bodies with the cursor.
+// It is attached to the world, so GetBodyA returns nil.
+type MouseJoint struct {
+	jointBase
+	localAnchor Vector
+	target      Vector
+
+	MaxForce     float32
+	Frequency    float32 // spring frequency in Hz
+	DampingRatio float32
+
+	rB         Vector
+	mass       mat22
+	c          Vector
+	gamma      float32
+	impulse    Vector
+	maxImpulse float32
+}
+
+// target is the grab point in pixels, it becomes the anchor on the body
+func CreateMouseJoint(b *Body, target Vector, maxForce, frequency, dampingRatio float32) (*MouseJoint, error) {
+	if b == nil {
+		return nil, errors.New("phygo: mouse joints need a body, got nil")
+	}
+	if err := checkJointCount(); err != nil {
+		return nil, err
+	}
+	target = VectorMul(target, 1/float32(ppu))
+
+	newJoint := &MouseJoint{
+		jointBase:    jointBase{bodyB: b, CollideConnected: true},
+		localAnchor:  localPoint(b, target),
+		target:       target,
+		MaxForce:     maxForce,
+		Frequency:    frequency,
+		DampingRatio: dampingRatio,
+	}
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func (j *MouseJoint) GetType() JointType {
+	return MouseJointType
+}
+
+func (j *MouseJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*MouseJoint)
+	if !ok {
+		d = &MouseJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *MouseJoint) hashState(h uint64) uint64 {
+	h = hashVector(h, j.localAnchor)
+	h = hashVector(h, j.target)
+	h = hashFloat(h, j.MaxForce)
+	h = hashFloat(h, j.Frequency)
+	h = hashFloat(h, j.DampingRatio)
+	return hashVector(h, j.impulse)
+}
+
+func (j *MouseJoint) SetTarget(target Vector) {
+	target = VectorMul(target, 1/float32(ppu))
+	if !VectorEquals(target, j.target) {
+		j.bodyB.SetAwake(true)
+	}
+	j.target = target
+}
+
+func (j *MouseJoint) GetTarget() Vector {
+	return VectorMul(j.target, ppu)
+}
+
+// Returns the anchor point on the body in pixels
+func (j *MouseJoint) GetAnchor() Vector {
+	return worldAnchor(j.bodyB, j.localAnchor)
+}
+
+// Returns the target, the joint has no body A
+func (j *MouseJoint) GetAnchorA() Vector {
+	return j.GetTarget()
+}
+
+func (j *MouseJoint) GetAnchorB() Vector {
+	return j.GetAnchor()
+}
+
+func (j *MouseJoint) prepare(dt float32) {
+	b := j.bodyB
+	h := dt * ppu
+
+	// spring stiffness and damping, the frequency is scaled to the solver's time unit
+	omega := 2 * math.Pi * j.Frequency / ppu
+	d := 2 * b.mass * j.DampingRatio * omega
+	k := b.mass * omega * omega
+
+	j.gamma = h * (d + float32(h*k))
+	if j.gamma != 0 {
+		j.gamma = 1 / j.gamma
+	}
+	beta := h * k * j.gamma
+
+	j.rB = rotateVector(j.localAnchor, b.Rotation)
+	invMass := b.invMass
+	invI := b.getInvInertia()
+
+	var K mat22
+	K.ex.X = invMass + float32(invI*j.rB.Y*j.rB.Y) + j.gamma
+	K.ex.Y = -invI * j.rB.X * j.rB.Y
+	K.ey.X = K.ex.Y
+	K.ey.Y = invMass + float32(invI*j.rB.X*j.rB.X) + j.gamma
+	j.mass = K.inverse()
+
+	j.c = VectorMul(VectorSubtract(VectorAdd(b.position, j.rB), j.target), beta)
+	j.maxImpulse = j.MaxForce * dt
+	j.impulse = VectorZero()
+}
+
+func (j *MouseJoint) solve(dt float32) {
+	b := j.bodyB
+
+	cdot := VectorAdd(b.Velocity, crossSV(b.AngularVelocity, j.rB))
+	impulse := j.mass.mulV(VectorMul(VectorAdd(VectorAdd(cdot, j.c), VectorMul(j.impulse, j.gamma)), -1))
+
+	oldImpulse := j.impulse
+	j.impulse.AddValue(impulse)
+	if VectorLenSqr(j.impulse) > j.maxImpulse*j.maxImpulse {
+		j.impulse = VectorMul(VectorNormalize(j.impulse), j.maxImpulse)
+	}
+	impulse = VectorSubtract(j.impulse, oldImpulse)
+
+	applyJointImpulse(b, impulse, VectorCrossProduct(j.rB, impulse))
+}
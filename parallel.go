@@ -0,0 +1,154 @@
+package phygo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// number of goroutines used by the narrowphase and the contact solver
+var workers = 1
+
+const (
+	pairChunk   = 64 // pairs handed to a worker at once
+	islandChunk = 1
+)
+
+// a pair that passed the broadphase and the result of its narrowphase test
+type contactPair struct {
+	bodyA, bodyB *Body
+
+	hit          bool
+	depth        float32
+	normal       Vector
+	contacts     [2]Vector
+	contactCount int
+}
+
+// storage reused every step
+var (
+	pairs []contactPair
+
+	// manifold indices grouped by island, island i owns
+	// islandManifolds[islandStarts[i]:islandStarts[i+1]]
+	islandManifolds []int
+	islandStarts    []int
+	islandRoots     []int
+	islandCounts    [maxBodies]int
+)
+
+// Sets the number of goroutines testing contact pairs and solving independent
+// islands, runtime.NumCPU() is a good value for large worlds. The results don't
+// depend on it, 1 (the default) runs everything on the calling goroutine and
+// doesn't allocate. Joints are always solved on the calling goroutine.
+func SetWorkers(n int) {
+	workers = max(n, 1)
+}
+
+func GetWorkers() int {
+	return workers
+}
+
+// calls fn on ranges covering [0, n), spread over the workers. Each range is
+// handled by a single worker so fn only has to write to its own indices.
+func parallelFor(n, chunk int, fn func(start, end int)) {
+	if workers <= 1 || n <= chunk {
+		fn(0, n)
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	work := func() {
+		defer wg.Done()
+		for {
+			end := int(next.Add(int64(chunk)))
+			start := end - chunk
+			if start >= n {
+				return
+			}
+			fn(start, min(end, n))
+		}
+	}
+
+	wg.Add(workers)
+	for i := 1; i < workers; i++ {
+		go work()
+	}
+	work()
+	wg.Wait()
+}
+
+func testPairs(start, end int) {
+	for i := start; i < end; i++ {
+		p := &pairs[i]
+		p.hit, p.depth, p.normal = CheckCollision(p.bodyA, p.bodyB)
+		if p.hit {
+			p.contacts, p.contactCount = findContactPoints(p.bodyA, p.bodyB)
+		}
+	}
+}
+
+// groups the manifolds by the island of their bodies, keeping their order
+// inside each island so every body sees the same impulses as in a serial run
+func buildContactIslands() {
+	for i, b := range bodies[:bodyCount] {
+		b.islandIndex = i
+		islandParent[i] = i
+		islandCounts[i] = 0
+	}
+	for i := range manifolds[:manifoldCount] {
+		unionIslands(manifolds[i].BodyA, manifolds[i].BodyB)
+	}
+
+	islandRoots = islandRoots[:0]
+	for i := range manifolds[:manifoldCount] {
+		root := manifoldIsland(&manifolds[i])
+		if islandCounts[root] == 0 {
+			islandRoots = append(islandRoots, root)
+		}
+		islandCounts[root]++
+	}
+
+	// the counts become the next free slot of each island
+	islandStarts = append(islandStarts[:0], 0)
+	for _, root := range islandRoots {
+		start := islandStarts[len(islandStarts)-1]
+		islandStarts = append(islandStarts, start+islandCounts[root])
+		islandCounts[root] = start
+	}
+
+	islandManifolds = islandManifolds[:0]
+	for range manifolds[:manifoldCount] {
+		islandManifolds = append(islandManifolds, 0)
+	}
+	for i := range manifolds[:manifoldCount] {
+		root := manifoldIsland(&manifolds[i])
+		islandManifolds[islandCounts[root]] = i
+		islandCounts[root]++
+	}
+}
+
+// static bodies don't join islands, the other body of the contact is never static
+func manifoldIsland(m *Manifold) int {
+	if m.BodyA.bodyType == StaticBody {
+		return findIsland(m.BodyB.islandIndex)
+	}
+	return findIsland(m.BodyA.islandIndex)
+}
+
+func solveIslands(start, end int) {
+	for _, index := range islandManifolds[islandStarts[start]:islandStarts[end]] {
+		resolveCollision(&manifolds[index])
+	}
+}
+
+func solveContacts() {
+	if workers <= 1 {
+		for i := range manifolds[:manifoldCount] {
+			resolveCollision(&manifolds[i])
+		}
+		return
+	}
+	buildContactIslands()
+	parallelFor(len(islandRoots), islandChunk, solveIslands)
+}
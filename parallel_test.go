@@ -0,0 +1,31 @@
+package phygo
+
+import "testing"
+
+// parallel runs give the same states as serial ones
+func TestParallelMatchesSerial(t *testing.T) {
+	scenes := []struct {
+		name  string
+		setup func(t testing.TB)
+	}{
+		{"mixed", setupMixedScene},
+		{"joints", setupJointScene},
+		{"circles", func(t testing.TB) {
+			gridScene(t, 150, 10, func(pos Vector) (*Body, error) {
+				return CreateBodyCircle(pos, 5, 1, false)
+			})
+		}},
+	}
+	for _, scene := range scenes {
+		t.Run(scene.name, func(t *testing.T) {
+			resetWorld(t)
+			scene.setup(t)
+			want := runHashes(300)
+
+			resetWorld(t)
+			SetWorkers(4)
+			scene.setup(t)
+			compareHashes(t, want, runHashes(300))
+		})
+	}
+}
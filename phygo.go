@@ -14,8 +14,13 @@ const (
 
 	ppu = 50 // pixels per unit
 
-	maxBodies   = 300
-	maxManifold = 1000
+	defaultMaxStepsPerFrame = 5
+
+	jointBaumgarte = 0.2 // fraction of the joint error corrected each step
+
+	maxBodies   = 8192
+	maxManifold = 16384
+	maxJoints   = 8192
 )
 
 // globals
@@ -23,10 +28,23 @@ var (
 	bodies        [maxBodies]*Body
 	bodyCount     = 0 // number of bodies
 	gravity       = NewVector(0, 1)
-	manifolds     [maxManifold]*Manifold
+	manifolds     [maxManifold]Manifold
 	manifoldCount = 0
+	joints        [maxJoints]Joint
+	jointCount    = 0
+
+	// contacts that didn't fit in manifolds during the current sub-step
+	droppedContacts = 0
+
+	usedIds [maxBodies + 1]bool // scratch space of getId
 
 	iterations = 32 // number of steps per frame
+
+	// fixed timestep mode, disabled when fixedTimestep is 0
+	fixedTimestep      float32 = 0
+	maxStepsPerFrame           = defaultMaxStepsPerFrame
+	accumulator        float32 = 0
+	interpolationAlpha float32 = 1
 )
 
 func SetIteration(i int) {
@@ -36,6 +54,10 @@ func SetIteration(i int) {
 func SetGravity(x, y float32) {
 	gravity.X = x
 	gravity.Y = y
+
+	for _, b := range bodies[:bodyCount] {
+		b.SetAwake(true)
+	}
 }
 
 func GetBody(index int) (bool, *Body) {
@@ -72,6 +94,8 @@ func RemoveBody(b *Body) {
 		return
 	}
 
+	removeBodyJoints(b)
+	wakeBodiesTouching(b)
 	bodies[index] = nil
 
 	for i := index; i+1 < bodyCount; i++ {
@@ -80,73 +104,220 @@ func RemoveBody(b *Body) {
 	bodyCount--
 }
 
+// returns the lowest id not used by another body
 func getId() int {
-	index := -1
-	for i := 0; i < maxBodies; i++ {
-		currentID := i
-
-		for j := 0; j < bodyCount; j++ {
-			if bodies[j].Id == currentID {
-				currentID++
-				break
-			}
+	// among bodyCount bodies one of the ids up to bodyCount is free
+	used := usedIds[:bodyCount+1]
+	clear(used)
+	for _, b := range bodies[:bodyCount] {
+		if b.Id >= 0 && b.Id < len(used) {
+			used[b.Id] = true
 		}
-
-		if currentID == i {
-			index = i
-			break
+	}
+	for id, u := range used {
+		if !u {
+			return id
 		}
 	}
-	return index
+	return -1
+}
+
+// Enables the fixed timestep mode: UpdatePhysics accumulates the frame time
+// and simulates whole steps of the given length, at most maxSteps per call.
+// A timestep of 0 disables it.
+func SetFixedTimestep(timestep float32, maxSteps int) {
+	if timestep < 0 {
+		timestep = 0
+	}
+	fixedTimestep = timestep
+	maxStepsPerFrame = ClampInt(maxSteps, 1, math.MaxInt32)
+	accumulator = 0
+	interpolationAlpha = 1
+
+	for _, b := range bodies[:bodyCount] {
+		b.savePreviousTransform()
+	}
+}
+
+// Returns how far the simulation is between the previous and the current
+// fixed step, used to interpolate rendering. Always 1 without a fixed timestep.
+func GetInterpolationAlpha() float32 {
+	return interpolationAlpha
 }
 
 func UpdatePhysics(time float32) {
+	t := startTimer()
+	stats = Stats{}
+	validationIssues = validationIssues[:0]
+
+	if recording != nil {
+		recordBeforeUpdate(time)
+		updatePhysics(time)
+		recordAfterUpdate()
+	} else {
+		updatePhysics(time)
+	}
+
+	finishStats(t)
+
+	if validation != nil && len(validationIssues) > 0 && validation.OnIssues != nil {
+		validation.OnIssues(validationIssues)
+	}
+}
+
+func updatePhysics(time float32) {
+	if fixedTimestep <= 0 {
+		simulate(time)
+		clearForces()
+		return
+	}
+
+	accumulator += time
+	steps := 0
+	for accumulator >= fixedTimestep && steps < maxStepsPerFrame {
+		for _, b := range bodies[:bodyCount] {
+			b.savePreviousTransform()
+		}
+		simulate(fixedTimestep)
+		accumulator -= fixedTimestep
+		steps++
+	}
+	// every step of the frame applies the same forces, a frame without steps
+	// keeps them for the next one
+	if steps > 0 {
+		clearForces()
+	}
+
+	// dropping the time that couldn't be simulated so slow frames don't pile up
+	if accumulator >= fixedTimestep {
+		accumulator = float32(math.Mod(float64(accumulator), float64(fixedTimestep)))
+	}
+	interpolationAlpha = accumulator / fixedTimestep
+}
+
+func simulate(time float32) {
+	wakeTouchedBodies()
+
 	for i := 0; i < iterations; i++ {
 		step(time, iterations)
+		if validation != nil {
+			validateStep(stats.Steps + i)
+		}
+	}
+	stats.Steps += iterations
+
+	t := startTimer()
+	updateSleep(time)
+	stats.Islands += t.elapsed()
+}
+
+func clearForces() {
+	for _, b := range bodies[:bodyCount] {
+		b.clearForces()
 	}
 }
 
 func step(time float32, iteration int) {
+	t := startTimer()
+
 	// movement step
 	for _, b := range bodies[:bodyCount] {
+		// sleeping bodies keep their last state
+		if b.isSleeping() {
+			continue
+		}
 		b.step(time, iteration)
 		b.IsOnGround = false
 		b.transformVertices()
 		b.updateAABB()
 	}
+	stats.Integration += t.elapsed()
 
-	// clearing the previous step manifold list
-	for i := manifoldCount - 1; i >= 0; i-- {
-		if manifold := manifolds[i]; manifold != nil {
-			manifold = nil
-		}
-	}
+	// clearing the previous step manifold list, the storage is reused
 	manifoldCount = 0
+	droppedContacts = 0
 
 	//collision step
+	t = startTimer()
+	pairs = pairs[:0]
 	for i := 0; i < bodyCount-1; i++ {
 		bodyA := bodies[i]
 		for j := i + 1; j < bodyCount; j++ {
 			bodyB := bodies[j]
 
-			if bodyA.IsStatic && bodyB.IsStatic {
+			// static and kinematic bodies never collide with each other
+			if !bodyA.IsDynamic() && !bodyB.IsDynamic() {
 				continue
 			}
 
+			if !bodyA.awake && !bodyB.awake {
+				continue
+			}
+
+			stats.CandidatePairs++
 			if !CheckCollisionAABBs(bodyA.aabb, bodyB.aabb) {
+				stats.AABBRejects++
 				continue
 			}
 
-			if ok, depth, normal := CheckCollision(bodyA, bodyB); ok {
-				cntPoints, cntCount := findContactPoints(*bodyA, *bodyB)
-				createManifold(bodyA, bodyB, normal, depth, cntPoints, cntCount)
+			if jointPreventsCollision(bodyA, bodyB) {
+				continue
 			}
+
+			pairs = append(pairs, contactPair{bodyA: bodyA, bodyB: bodyB})
+		}
+	}
+	stats.Broadphase += t.elapsed()
+
+	t = startTimer()
+	stats.SATTests += len(pairs)
+	parallelFor(len(pairs), pairChunk, testPairs)
+
+	// merging in the pair order keeps the manifolds independent of the workers
+	for i := range pairs {
+		p := &pairs[i]
+		if !p.hit {
+			continue
 		}
+		// an awake body touching a sleeping one wakes it up
+		if p.bodyA.isSleeping() {
+			p.bodyA.SetAwake(true)
+		}
+		if p.bodyB.isSleeping() {
+			p.bodyB.SetAwake(true)
+		}
+		createManifold(p.bodyA, p.bodyB, p.normal, p.depth, p.contacts, p.contactCount)
 	}
+	stats.Narrowphase += t.elapsed()
+	stats.Manifolds += manifoldCount
+
+	t = startTimer()
 
-	for _, m := range manifolds[:manifoldCount] {
-		resolveCollision(m)
+	// set before solving as the islands solved in parallel share the static bodies
+	for i := range manifolds[:manifoldCount] {
+		m := &manifolds[i]
+		if !m.BodyA.IsOnGround {
+			m.BodyA.IsOnGround = m.Normal.Y > 0
+		}
+		if !m.BodyB.IsOnGround {
+			m.BodyB.IsOnGround = m.Normal.Y < 0
+		}
 	}
+	solveContacts()
+
+	// joint step
+	dt := time / float32(iteration)
+	for _, j := range joints[:jointCount] {
+		if wakeJoint(j) {
+			j.prepare(dt)
+		}
+	}
+	for _, j := range joints[:jointCount] {
+		if jointIsAwake(j) {
+			j.solve(dt)
+		}
+	}
+	stats.Solver += t.elapsed()
 }
 
 func resolveCollision(manifold *Manifold) {
@@ -156,19 +327,13 @@ func resolveCollision(manifold *Manifold) {
 	contactPoints := manifold.Contacts
 	contactCount := manifold.ContactCount
 	depth := manifold.Depth
-
-	if !bodyA.IsOnGround {
-		bodyA.IsOnGround = manifold.Normal.Y > 0
-	}
-
-	if !bodyB.IsOnGround {
-		bodyB.IsOnGround = manifold.Normal.Y < 0
-	}
+	// only dynamic bodies are written, the others can be shared by islands solved in parallel
+	dynamicA, dynamicB := bodyA.IsDynamic(), bodyB.IsDynamic()
 
 	// separating overlapping bodies
-	if bodyA.IsStatic {
+	if !dynamicA {
 		bodyB.move(VectorMul(normal, depth))
-	} else if bodyB.IsStatic {
+	} else if !dynamicB {
 		bodyA.move(VectorMul(normal, -depth))
 	} else {
 		bodyA.move(VectorMul(normal, -depth/2))
@@ -207,7 +372,7 @@ func resolveCollision(manifold *Manifold) {
 		raPerpDotN := VectorDotProduct(raPerp, normal)
 		rbPerpDotN := VectorDotProduct(rbPerp, normal)
 
-		denom := bodyA.invMass + bodyB.invMass + raPerpDotN*raPerpDotN*bodyA.invInertia + rbPerpDotN*rbPerpDotN*bodyB.invInertia
+		denom := bodyA.invMass + bodyB.invMass + float32(raPerpDotN*raPerpDotN*bodyA.invInertia) + float32(rbPerpDotN*rbPerpDotN*bodyB.invInertia)
 		j := -(1 + e) * rvProj
 		j /= denom
 		j /= float32(contactCount)
@@ -221,13 +386,17 @@ func resolveCollision(manifold *Manifold) {
 		ra := raList[i]
 		rb := rbList[i]
 
-		bodyA.Velocity.AddValue(VectorMul(imp, -bodyA.invMass))
-		if !bodyA.RotationDisabled {
-			bodyA.AngularVelocity += -VectorCrossProduct(ra, imp) * bodyA.invInertia
+		if dynamicA {
+			bodyA.Velocity.AddValue(VectorMul(imp, -bodyA.invMass))
+			if !bodyA.RotationDisabled {
+				bodyA.AngularVelocity += float32(-VectorCrossProduct(ra, imp) * bodyA.invInertia)
+			}
 		}
-		bodyB.Velocity.AddValue(VectorMul(imp, bodyB.invMass))
-		if !bodyB.RotationDisabled {
-			bodyB.AngularVelocity += VectorCrossProduct(rb, imp) * bodyB.invInertia
+		if dynamicB {
+			bodyB.Velocity.AddValue(VectorMul(imp, bodyB.invMass))
+			if !bodyB.RotationDisabled {
+				bodyB.AngularVelocity += float32(VectorCrossProduct(rb, imp) * bodyB.invInertia)
+			}
 		}
 	}
 
@@ -261,7 +430,7 @@ func resolveCollision(manifold *Manifold) {
 		raPerpDotT := VectorDotProduct(raPerp, tangent)
 		rbPerpDotT := VectorDotProduct(rbPerp, tangent)
 
-		denom := bodyA.invMass + bodyB.invMass + raPerpDotT*raPerpDotT*bodyA.invInertia + rbPerpDotT*rbPerpDotT*bodyB.invInertia
+		denom := bodyA.invMass + bodyB.invMass + float32(raPerpDotT*raPerpDotT*bodyA.invInertia) + float32(rbPerpDotT*rbPerpDotT*bodyB.invInertia)
 		jt := -VectorDotProduct(relativeVelocity, tangent)
 		jt /= denom
 		jt /= float32(contactCount)
@@ -282,24 +451,30 @@ func resolveCollision(manifold *Manifold) {
 		ra := raList[i]
 		rb := rbList[i]
 
-		bodyA.Velocity.AddValue(VectorMul(f, -bodyA.invMass))
-		if !bodyA.RotationDisabled {
-			bodyA.AngularVelocity += -VectorCrossProduct(ra, f) * bodyA.invInertia
+		if dynamicA {
+			bodyA.Velocity.AddValue(VectorMul(f, -bodyA.invMass))
+			if !bodyA.RotationDisabled {
+				bodyA.AngularVelocity += float32(-VectorCrossProduct(ra, f) * bodyA.invInertia)
+			}
 		}
-		bodyB.Velocity.AddValue(VectorMul(f, bodyB.invMass))
-		if !bodyB.RotationDisabled {
-			bodyB.AngularVelocity += VectorCrossProduct(rb, f) * bodyB.invInertia
+		if dynamicB {
+			bodyB.Velocity.AddValue(VectorMul(f, bodyB.invMass))
+			if !bodyB.RotationDisabled {
+				bodyB.AngularVelocity += float32(VectorCrossProduct(rb, f) * bodyB.invInertia)
+			}
 		}
 	}
 }
 
 func Close() {
-	for i := manifoldCount - 1; i >= 0; i-- {
-		if manifold := manifolds[i]; manifold != nil {
-			manifold = nil
-		}
+	for i := jointCount - 1; i >= 0; i-- {
+		RemoveJoint(joints[i])
 	}
 
+	// dropping the contacts so they don't keep the removed bodies alive
+	clear(manifolds[:manifoldCount])
+	manifoldCount = 0
+
 	for i := bodyCount - 1; i >= 0; i-- {
 		RemoveBody(bodies[i])
 	}
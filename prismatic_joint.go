@@ -0,0 +1,151 @@
+package phygo
+
+// PrismaticJoint lets body B slide along an axis fixed in body A while
+// preventing relative rotation. It can be driven by a motor.
+type PrismaticJoint struct {
+	jointBase
+	localAnchorA   Vector
+	localAnchorB   Vector
+	localAxisA     Vector
+	referenceAngle float32
+
+	EnableMotor   bool
+	MotorSpeed    float32 // same units as Velocity
+	MaxMotorForce float32
+
+	rA, rB       Vector
+	axis, perp   Vector
+	a1, a2       float32
+	s1, s2       float32
+	mass         mat22
+	bias         Vector
+	motorMass    float32
+	motorImpulse float32
+}
+
+// anchor is a world point in pixels, axis is the world direction of the slide
+func CreatePrismaticJoint(bodyA, bodyB *Body, anchor, axis Vector) (*PrismaticJoint, error) {
+	if err := checkJointInput(bodyA, bodyB); err != nil {
+		return nil, err
+	}
+	anchor = VectorMul(anchor, 1/float32(ppu))
+
+	newJoint := &PrismaticJoint{
+		jointBase:      jointBase{bodyA: bodyA, bodyB: bodyB},
+		localAnchorA:   localPoint(bodyA, anchor),
+		localAnchorB:   localPoint(bodyB, anchor),
+		localAxisA:     rotateVector(VectorNormalize(axis), -bodyA.Rotation),
+		referenceAngle: bodyB.Rotation - bodyA.Rotation,
+	}
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func (j *PrismaticJoint) GetType() JointType {
+	return PrismaticJointType
+}
+
+func (j *PrismaticJoint) GetAnchorA() Vector {
+	return worldAnchor(j.bodyA, j.localAnchorA)
+}
+
+func (j *PrismaticJoint) GetAnchorB() Vector {
+	return worldAnchor(j.bodyB, j.localAnchorB)
+}
+
+func (j *PrismaticJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*PrismaticJoint)
+	if !ok {
+		d = &PrismaticJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *PrismaticJoint) hashState(h uint64) uint64 {
+	h = hashBool(h, j.EnableMotor)
+	h = hashFloat(h, j.MotorSpeed)
+	h = hashFloat(h, j.MaxMotorForce)
+	return hashFloat(h, j.motorImpulse)
+}
+
+// Returns how far body B moved along the axis in pixels
+func (j *PrismaticJoint) GetJointTranslation() float32 {
+	return j.translation() * ppu
+}
+
+func (j *PrismaticJoint) translation() float32 {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	pA := VectorAdd(bodyA.position, rotateVector(j.localAnchorA, bodyA.Rotation))
+	pB := VectorAdd(bodyB.position, rotateVector(j.localAnchorB, bodyB.Rotation))
+	return VectorDotProduct(VectorSubtract(pB, pA), rotateVector(j.localAxisA, bodyA.Rotation))
+}
+
+func (j *PrismaticJoint) prepare(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	h := dt * ppu
+
+	j.rA = rotateVector(j.localAnchorA, bodyA.Rotation)
+	j.rB = rotateVector(j.localAnchorB, bodyB.Rotation)
+	d := VectorSubtract(VectorAdd(bodyB.position, j.rB), VectorAdd(bodyA.position, j.rA))
+
+	mA, mB := bodyA.invMass, bodyB.invMass
+	iA, iB := bodyA.getInvInertia(), bodyB.getInvInertia()
+
+	// motor axis
+	j.axis = rotateVector(j.localAxisA, bodyA.Rotation)
+	j.a1 = VectorCrossProduct(VectorAdd(d, j.rA), j.axis)
+	j.a2 = VectorCrossProduct(j.rB, j.axis)
+	j.motorMass = mA + mB + float32(iA*j.a1*j.a1) + float32(iB*j.a2*j.a2)
+	if j.motorMass > 0 {
+		j.motorMass = 1 / j.motorMass
+	}
+
+	// point to line and angle constraints
+	j.perp = crossSV(1, j.axis)
+	j.s1 = VectorCrossProduct(VectorAdd(d, j.rA), j.perp)
+	j.s2 = VectorCrossProduct(j.rB, j.perp)
+
+	var K mat22
+	K.ex.X = mA + mB + float32(iA*j.s1*j.s1) + float32(iB*j.s2*j.s2)
+	K.ex.Y = float32(iA*j.s1) + float32(iB*j.s2)
+	K.ey.X = K.ex.Y
+	K.ey.Y = iA + iB
+	if K.ey.Y == 0 {
+		// both bodies have fixed rotation
+		K.ey.Y = 1
+	}
+	j.mass = K.inverse()
+
+	c := NewVector(VectorDotProduct(j.perp, d), bodyB.Rotation-bodyA.Rotation-j.referenceAngle)
+	j.bias = VectorMul(c, jointBaumgarte/h)
+	j.motorImpulse = 0
+}
+
+func (j *PrismaticJoint) solve(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+
+	if j.EnableMotor {
+		cdot := VectorDotProduct(j.axis, VectorSubtract(bodyB.Velocity, bodyA.Velocity)) + float32(j.a2*bodyB.AngularVelocity) - float32(j.a1*bodyA.AngularVelocity)
+		impulse := j.motorMass * (j.MotorSpeed - cdot)
+		oldImpulse := j.motorImpulse
+		maxImpulse := j.MaxMotorForce * dt
+		j.motorImpulse = ClampFloat(j.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse = j.motorImpulse - oldImpulse
+
+		p := VectorMul(j.axis, impulse)
+		applyJointImpulse(bodyA, VectorMul(p, -1), -impulse*j.a1)
+		applyJointImpulse(bodyB, p, impulse*j.a2)
+	}
+
+	cdot := NewVector(
+		VectorDotProduct(j.perp, VectorSubtract(bodyB.Velocity, bodyA.Velocity))+float32(j.s2*bodyB.AngularVelocity)-float32(j.s1*bodyA.AngularVelocity),
+		bodyB.AngularVelocity-bodyA.AngularVelocity,
+	)
+	impulse := j.mass.mulV(VectorMul(VectorAdd(cdot, j.bias), -1))
+
+	p := VectorMul(j.perp, impulse.X)
+	applyJointImpulse(bodyA, VectorMul(p, -1), -(float32(impulse.X*j.s1) + impulse.Y))
+	applyJointImpulse(bodyB, p, float32(impulse.X*j.s2)+impulse.Y)
+}
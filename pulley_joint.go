@@ -0,0 +1,141 @@
+package phygo
+
+import "fmt"
+
+// PulleyJoint connects two bodies with an ideal rope running over two fixed
+// ground anchors: lengthA + Ratio * lengthB stays constant.
+type PulleyJoint struct {
+	jointBase
+	groundAnchorA Vector
+	groundAnchorB Vector
+	localAnchorA  Vector
+	localAnchorB  Vector
+	constant      float32
+	ratio         float32
+
+	rA, rB Vector
+	uA, uB Vector
+	mass   float32
+	bias   float32
+}
+
+// all anchors are world points in pixels
+func CreatePulleyJoint(bodyA, bodyB *Body, groundAnchorA, groundAnchorB, anchorA, anchorB Vector, ratio float32) (*PulleyJoint, error) {
+	if err := checkJointInput(bodyA, bodyB); err != nil {
+		return nil, err
+	}
+	if !(ratio > 0) || isInvalid(ratio) {
+		return nil, fmt.Errorf("phygo: pulley ratio must be positive, got %v", ratio)
+	}
+	groundAnchorA = VectorMul(groundAnchorA, 1/float32(ppu))
+	groundAnchorB = VectorMul(groundAnchorB, 1/float32(ppu))
+	anchorA = VectorMul(anchorA, 1/float32(ppu))
+	anchorB = VectorMul(anchorB, 1/float32(ppu))
+
+	newJoint := &PulleyJoint{
+		jointBase:     jointBase{bodyA: bodyA, bodyB: bodyB, CollideConnected: true},
+		groundAnchorA: groundAnchorA,
+		groundAnchorB: groundAnchorB,
+		localAnchorA:  localPoint(bodyA, anchorA),
+		localAnchorB:  localPoint(bodyB, anchorB),
+		constant:      VectorDistance(anchorA, groundAnchorA) + float32(ratio*VectorDistance(anchorB, groundAnchorB)),
+		ratio:         ratio,
+	}
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func (j *PulleyJoint) GetType() JointType {
+	return PulleyJointType
+}
+
+func (j *PulleyJoint) GetAnchorA() Vector {
+	return worldAnchor(j.bodyA, j.localAnchorA)
+}
+
+func (j *PulleyJoint) GetAnchorB() Vector {
+	return worldAnchor(j.bodyB, j.localAnchorB)
+}
+
+func (j *PulleyJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*PulleyJoint)
+	if !ok {
+		d = &PulleyJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *PulleyJoint) hashState(h uint64) uint64 {
+	h = hashFloat(h, j.constant)
+	return hashFloat(h, j.ratio)
+}
+
+func (j *PulleyJoint) GetGroundAnchorA() Vector {
+	return VectorMul(j.groundAnchorA, ppu)
+}
+
+func (j *PulleyJoint) GetGroundAnchorB() Vector {
+	return VectorMul(j.groundAnchorB, ppu)
+}
+
+func (j *PulleyJoint) GetRatio() float32 {
+	return j.ratio
+}
+
+// Returns the rope length between ground anchor A and body A in pixels
+func (j *PulleyJoint) GetLengthA() float32 {
+	p := VectorAdd(j.bodyA.position, rotateVector(j.localAnchorA, j.bodyA.Rotation))
+	return VectorDistance(p, j.groundAnchorA) * ppu
+}
+
+// Returns the rope length between ground anchor B and body B in pixels
+func (j *PulleyJoint) GetLengthB() float32 {
+	p := VectorAdd(j.bodyB.position, rotateVector(j.localAnchorB, j.bodyB.Rotation))
+	return VectorDistance(p, j.groundAnchorB) * ppu
+}
+
+func (j *PulleyJoint) prepare(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	h := dt * ppu
+
+	j.rA = rotateVector(j.localAnchorA, bodyA.Rotation)
+	j.rB = rotateVector(j.localAnchorB, bodyB.Rotation)
+
+	// rope directions from the ground anchors to the bodies
+	j.uA = VectorSubtract(VectorAdd(bodyA.position, j.rA), j.groundAnchorA)
+	j.uB = VectorSubtract(VectorAdd(bodyB.position, j.rB), j.groundAnchorB)
+	lengthA := VectorLen(j.uA)
+	lengthB := VectorLen(j.uB)
+	j.uA = VectorNormalize(j.uA)
+	j.uB = VectorNormalize(j.uB)
+
+	ruA := VectorCrossProduct(j.rA, j.uA)
+	ruB := VectorCrossProduct(j.rB, j.uB)
+	mA := bodyA.invMass + float32(bodyA.getInvInertia()*ruA*ruA)
+	mB := bodyB.invMass + float32(bodyB.getInvInertia()*ruB*ruB)
+
+	j.mass = mA + float32(j.ratio*j.ratio*mB)
+	if j.mass > 0 {
+		j.mass = 1 / j.mass
+	}
+
+	c := j.constant - lengthA - float32(j.ratio*lengthB)
+	j.bias = c * jointBaumgarte / h
+}
+
+func (j *PulleyJoint) solve(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+
+	vpA := VectorAdd(bodyA.Velocity, crossSV(bodyA.AngularVelocity, j.rA))
+	vpB := VectorAdd(bodyB.Velocity, crossSV(bodyB.AngularVelocity, j.rB))
+
+	cdot := -VectorDotProduct(j.uA, vpA) - float32(j.ratio*VectorDotProduct(j.uB, vpB))
+	impulse := -j.mass * (cdot + j.bias)
+
+	pA := VectorMul(j.uA, -impulse)
+	pB := VectorMul(j.uB, -j.ratio*impulse)
+	applyJointImpulse(bodyA, pA, VectorCrossProduct(j.rA, pA))
+	applyJointImpulse(bodyB, pB, VectorCrossProduct(j.rB, pB))
+}
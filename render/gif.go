@@ -0,0 +1,79 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"io"
+	"math"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+type GIFOptions struct {
+	Width, Height int
+	// number of frames written, at least 1
+	Frames int
+	// UpdatePhysics calls between frames, 1 when 0
+	StepsPerFrame int
+	// time passed to UpdatePhysics, 1/60 when 0
+	Dt         float32
+	Background color.RGBA
+	// what is drawn, DrawShapes when 0
+	Flags phygo.DebugDrawFlags
+}
+
+// Steps the world and writes an animated GIF of it. The first frame is the
+// state before stepping.
+func WriteGIF(w io.Writer, opts GIFOptions) error {
+	if opts.Width <= 0 || opts.Height <= 0 {
+		return fmt.Errorf("render: image size must be positive, got %dx%d", opts.Width, opts.Height)
+	}
+	if opts.Frames <= 0 {
+		return errors.New("render: a GIF needs at least one frame")
+	}
+	if opts.StepsPerFrame <= 0 {
+		opts.StepsPerFrame = 1
+	}
+	if opts.Dt <= 0 {
+		opts.Dt = 1.0 / 60
+	}
+	if opts.Flags == 0 {
+		opts.Flags = phygo.DrawShapes
+	}
+
+	// frame delays are in hundredths of a second
+	delay := max(int(math.Round(float64(opts.Dt)*float64(opts.StepsPerFrame)*100)), 1)
+
+	r := NewImage(opts.Width, opts.Height)
+	anim := &gif.GIF{}
+	// frames only have a few distinct colors, looking each up once is much faster than draw.Draw
+	indices := map[color.RGBA]uint8{}
+	for frame := 0; frame < opts.Frames; frame++ {
+		if frame > 0 {
+			for i := 0; i < opts.StepsPerFrame; i++ {
+				phygo.UpdatePhysics(opts.Dt)
+			}
+		}
+
+		r.Clear(opts.Background)
+		phygo.DrawDebug(r, opts.Flags)
+
+		paletted := image.NewPaletted(r.img.Bounds(), palette.Plan9)
+		for i := 0; i < len(r.img.Pix); i += 4 {
+			c := color.RGBA{r.img.Pix[i], r.img.Pix[i+1], r.img.Pix[i+2], r.img.Pix[i+3]}
+			index, ok := indices[c]
+			if !ok {
+				index = uint8(paletted.Palette.Index(c))
+				indices[c] = index
+			}
+			paletted.Pix[i/4] = index
+		}
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+)
+
+func TestWriteGIF(t *testing.T) {
+	_, ball := smallWorld(t)
+	start := ball.GetPos()
+
+	var buf bytes.Buffer
+	opts := GIFOptions{Width: 80, Height: 40, Frames: 5, StepsPerFrame: 2, Dt: 1.0 / 50, Background: black}
+	if err := WriteGIF(&buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 5 {
+		t.Errorf("%d frames, expected 5", len(anim.Image))
+	}
+	for i, delay := range anim.Delay {
+		// 2 steps of 1/50s
+		if delay != 4 {
+			t.Errorf("frame %d has a delay of %d, expected 4", i, delay)
+		}
+	}
+	if anim.Config.Width != 80 || anim.Config.Height != 40 {
+		t.Errorf("size %dx%d, expected 80x40", anim.Config.Width, anim.Config.Height)
+	}
+	// the first frame is drawn before stepping, 8 steps follow
+	if ball.GetPos().Y <= start.Y {
+		t.Errorf("ball at %v, expected it to fall from %v", ball.GetPos(), start)
+	}
+}
+
+func TestWriteGIFErrors(t *testing.T) {
+	smallWorld(t)
+	for _, opts := range []GIFOptions{
+		{Width: 80, Height: 40},
+		{Width: 80, Height: 40, Frames: -1},
+		{Width: 0, Height: 40, Frames: 1},
+		{Width: 80, Height: -5, Frames: 1},
+	} {
+		var buf bytes.Buffer
+		if err := WriteGIF(&buf, opts); err == nil {
+			t.Errorf("wrote a GIF with %+v", opts)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%d bytes written with %+v, expected none", buf.Len(), opts)
+		}
+	}
+}
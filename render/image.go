@@ -0,0 +1,242 @@
+// Package render draws phygo worlds without a window or GPU, to images,
+// SVG documents and animated GIFs, for generated previews and visual
+// regression artifacts. Both renderers implement phygo.DebugDraw.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+// length in pixels of the axes drawn by DrawTransform
+const axisLength = 15
+
+var (
+	xAxisColor = color.RGBA{230, 51, 51, 255}
+	yAxisColor = color.RGBA{51, 230, 51, 255}
+)
+
+// Image draws to an image.RGBA, filling shapes with a translucent version of their color
+type Image struct {
+	img *image.RGBA
+}
+
+func NewImage(width, height int) *Image {
+	return &Image{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (r *Image) RGBA() *image.RGBA {
+	return r.img
+}
+
+func (r *Image) Clear(c color.RGBA) {
+	draw.Draw(r.img, r.img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// Returns an image of the current state of the world
+func RenderImage(width, height int, background color.RGBA, flags phygo.DebugDrawFlags) *image.RGBA {
+	r := NewImage(width, height)
+	r.Clear(background)
+	phygo.DrawDebug(r, flags)
+	return r.img
+}
+
+func fillColor(c color.RGBA) color.RGBA {
+	return color.RGBA{c.R / 2, c.G / 2, c.B / 2, c.A / 2}
+}
+
+// blends c over the pixel, c being alpha premultiplied as color.RGBA is
+func (r *Image) blend(x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(r.img.Rect)) {
+		return
+	}
+	i := r.img.PixOffset(x, y)
+	pix := r.img.Pix[i : i+4 : i+4]
+	inv := 255 - uint32(c.A)
+	pix[0] = uint8(uint32(c.R) + uint32(pix[0])*inv/255)
+	pix[1] = uint8(uint32(c.G) + uint32(pix[1])*inv/255)
+	pix[2] = uint8(uint32(c.B) + uint32(pix[2])*inv/255)
+	pix[3] = uint8(uint32(c.A) + uint32(pix[3])*inv/255)
+}
+
+func (r *Image) DrawPolygon(vertices []phygo.Vector, c color.RGBA) {
+	if len(vertices) == 0 {
+		return
+	}
+
+	// even-odd scanline fill, sampling pixel centers
+	minY, maxY := vertices[0].Y, vertices[0].Y
+	for _, v := range vertices {
+		minY = min(minY, v.Y)
+		maxY = max(maxY, v.Y)
+	}
+	bounds := r.img.Rect
+	fill := fillColor(c)
+	var crossings []float32
+	startY, endY := clampRange(math.Floor(float64(minY)), math.Ceil(float64(maxY)), bounds.Min.Y, bounds.Max.Y)
+	for y := startY; y <= endY; y++ {
+		sy := float32(y) + 0.5
+		crossings = crossings[:0]
+		for i, a := range vertices {
+			b := vertices[(i+1)%len(vertices)]
+			if (a.Y <= sy) != (b.Y <= sy) {
+				crossings = append(crossings, a.X+(sy-a.Y)/(b.Y-a.Y)*(b.X-a.X))
+			}
+		}
+		sortFloats(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			start, end := clampRange(float64(crossings[i]-0.5), float64(crossings[i+1]-0.5), bounds.Min.X, bounds.Max.X)
+			for x := start; x <= end; x++ {
+				r.blend(x, y, fill)
+			}
+		}
+	}
+
+	for i, a := range vertices {
+		r.DrawSegment(a, vertices[(i+1)%len(vertices)], c)
+	}
+}
+
+// insertion sort, polygons only have a few crossings per row
+func sortFloats(values []float32) {
+	for i := 1; i < len(values); i++ {
+		for j := i; j > 0 && values[j] < values[j-1]; j-- {
+			values[j], values[j-1] = values[j-1], values[j]
+		}
+	}
+}
+
+// Returns the pixels from ceil(lo) to floor(hi) that are inside [min, max),
+// an empty range when there are none or the bounds aren't finite
+func clampRange(lo, hi float64, min, max int) (int, int) {
+	l, h := math.Ceil(lo), math.Floor(hi)
+	if !(l < float64(max)) || !(h >= float64(min)) {
+		return 0, -1
+	}
+	return int(math.Max(l, float64(min))), int(math.Min(h, float64(max-1)))
+}
+
+func (r *Image) DrawCircle(center phygo.Vector, radius float32, c color.RGBA) {
+	fill := fillColor(c)
+	bounds := r.img.Rect
+	minX, maxX := clampRange(math.Floor(float64(center.X-radius-1)), math.Ceil(float64(center.X+radius+1)), bounds.Min.X, bounds.Max.X)
+	minY, maxY := clampRange(math.Floor(float64(center.Y-radius-1)), math.Ceil(float64(center.Y+radius+1)), bounds.Min.Y, bounds.Max.Y)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			d := phygo.VectorDistance(center, phygo.NewVector(float32(x)+0.5, float32(y)+0.5))
+			switch {
+			case float32(math.Abs(float64(d-radius))) <= 0.5:
+				r.blend(x, y, c)
+			case d < radius:
+				r.blend(x, y, fill)
+			}
+		}
+	}
+}
+
+// Bresenham's line, on the part of the segment inside the image
+func (r *Image) DrawSegment(a, b phygo.Vector, c color.RGBA) {
+	a, b, ok := clipSegment(a, b, r.img.Rect)
+	if !ok {
+		return
+	}
+	x0, y0 := int(math.Floor(float64(a.X))), int(math.Floor(float64(a.Y)))
+	x1, y1 := int(math.Floor(float64(b.X))), int(math.Floor(float64(b.Y)))
+
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		r.blend(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * err; e2 >= dy {
+			err += dy
+			x0 += sx
+		} else {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Liang-Barsky clipping of the segment to the rectangle, false when none of it is inside
+func clipSegment(a, b phygo.Vector, rect image.Rectangle) (phygo.Vector, phygo.Vector, bool) {
+	dx, dy := float64(b.X-a.X), float64(b.Y-a.Y)
+	t0, t1 := 0.0, 1.0
+	// each edge gives the distance p along the segment direction and q to the edge
+	edges := [4][2]float64{
+		{-dx, float64(a.X) - float64(rect.Min.X)},
+		{dx, float64(rect.Max.X) - float64(a.X)},
+		{-dy, float64(a.Y) - float64(rect.Min.Y)},
+		{dy, float64(rect.Max.Y) - float64(a.Y)},
+	}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if math.IsNaN(p) || math.IsNaN(q) || math.IsInf(q, 0) {
+			return a, b, false
+		}
+		switch {
+		case p == 0:
+			if q < 0 {
+				return a, b, false
+			}
+		case p < 0:
+			t0 = math.Max(t0, q/p)
+		default:
+			t1 = math.Min(t1, q/p)
+		}
+	}
+	if t0 > t1 {
+		return a, b, false
+	}
+	at := func(t float64) phygo.Vector {
+		return phygo.NewVector(float32(float64(a.X)+t*dx), float32(float64(a.Y)+t*dy))
+	}
+	return at(t0), at(t1), true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func (r *Image) DrawPoint(p phygo.Vector, size float32, c color.RGBA) {
+	half := size / 2
+	bounds := r.img.Rect
+	// the pixels from floor(p-half) to before ceil(p+half)
+	minX, maxX := clampRange(math.Floor(float64(p.X-half)), math.Ceil(float64(p.X+half))-1, bounds.Min.X, bounds.Max.X)
+	minY, maxY := clampRange(math.Floor(float64(p.Y-half)), math.Ceil(float64(p.Y+half))-1, bounds.Min.Y, bounds.Max.Y)
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			r.blend(x, y, c)
+		}
+	}
+}
+
+func (r *Image) DrawTransform(pos phygo.Vector, rotation float32) {
+	xAxis, yAxis := axes(pos, rotation)
+	r.DrawSegment(pos, xAxis, xAxisColor)
+	r.DrawSegment(pos, yAxis, yAxisColor)
+}
+
+// returns the end points of the drawn axes
+func axes(pos phygo.Vector, rotation float32) (phygo.Vector, phygo.Vector) {
+	sin, cos := math.Sincos(float64(rotation))
+	x := phygo.NewVector(pos.X+float32(cos*axisLength), pos.Y+float32(sin*axisLength))
+	y := phygo.NewVector(pos.X-float32(sin*axisLength), pos.Y+float32(cos*axisLength))
+	return x, y
+}
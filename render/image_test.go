@@ -0,0 +1,113 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+var white = color.RGBA{255, 255, 255, 255}
+
+func TestClipSegment(t *testing.T) {
+	rect := image.Rect(0, 0, 10, 10)
+	tests := []struct {
+		name         string
+		a, b         phygo.Vector
+		ok           bool
+		clipA, clipB phygo.Vector
+	}{
+		{"inside", phygo.NewVector(1, 1), phygo.NewVector(8, 5), true, phygo.NewVector(1, 1), phygo.NewVector(8, 5)},
+		{"crossing", phygo.NewVector(-10, 5), phygo.NewVector(20, 5), true, phygo.NewVector(0, 5), phygo.NewVector(10, 5)},
+		{"diagonal", phygo.NewVector(-5, -5), phygo.NewVector(15, 15), true, phygo.NewVector(0, 0), phygo.NewVector(10, 10)},
+		{"outside", phygo.NewVector(-5, -5), phygo.NewVector(-1, 20), false, phygo.Vector{}, phygo.Vector{}},
+		{"parallel outside", phygo.NewVector(-10, 20), phygo.NewVector(20, 20), false, phygo.Vector{}, phygo.Vector{}},
+		{"huge", phygo.NewVector(-1e9, 5), phygo.NewVector(1e9, 5), true, phygo.NewVector(0, 5), phygo.NewVector(10, 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, ok := clipSegment(tt.a, tt.b, rect)
+			if ok != tt.ok || ok && (a != tt.clipA || b != tt.clipB) {
+				t.Errorf("clipped to %v %v %v, expected %v %v %v", a, b, ok, tt.clipA, tt.clipB, tt.ok)
+			}
+		})
+	}
+}
+
+// shapes far larger than the image only touch the pixels inside it
+func TestDrawClipped(t *testing.T) {
+	r := NewImage(20, 10)
+	r.DrawSegment(phygo.NewVector(-1e9, 5), phygo.NewVector(1e9, 5), white)
+	for x := 0; x < 20; x++ {
+		if got := r.RGBA().RGBAAt(x, 5); got != white {
+			t.Fatalf("pixel (%d, 5) is %v, expected the line", x, got)
+		}
+	}
+
+	r.Clear(color.RGBA{})
+	r.DrawCircle(phygo.NewVector(10, 5), 1e6, white)
+	if got := r.RGBA().RGBAAt(0, 0); got != fillColor(white) {
+		t.Errorf("pixel (0, 0) is %v, expected the circle fill", got)
+	}
+
+	r.Clear(color.RGBA{})
+	r.DrawPolygon([]phygo.Vector{{X: -1e9, Y: -1e9}, {X: 1e9, Y: -1e9}, {X: 1e9, Y: 1e9}, {X: -1e9, Y: 1e9}}, white)
+	if got := r.RGBA().RGBAAt(19, 9); got != fillColor(white) {
+		t.Errorf("pixel (19, 9) is %v, expected the polygon fill", got)
+	}
+
+	r.Clear(color.RGBA{})
+	r.DrawCircle(phygo.NewVector(-100, -100), 5, white)
+	r.DrawPoint(phygo.NewVector(100, 100), 4, white)
+	for i := 0; i < len(r.RGBA().Pix); i++ {
+		if r.RGBA().Pix[i] != 0 {
+			t.Fatal("shapes outside the image drew into it")
+		}
+	}
+}
+
+// a static box and a dynamic ball on a black background
+func smallWorld(t *testing.T) (box, ball *phygo.Body) {
+	t.Helper()
+	phygo.Close()
+	t.Cleanup(phygo.Close)
+	var err error
+	if box, err = phygo.CreateBodyRectangle(phygo.NewVector(20, 20), 20, 20, 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if ball, err = phygo.CreateBodyCircle(phygo.NewVector(60, 20), 10, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	return box, ball
+}
+
+var black = color.RGBA{0, 0, 0, 255}
+
+func TestRenderImage(t *testing.T) {
+	smallWorld(t)
+	img := RenderImage(80, 40, black, phygo.DrawShapes)
+	if img.Bounds() != image.Rect(0, 0, 80, 40) {
+		t.Fatalf("bounds %v, expected 80x40", img.Bounds())
+	}
+
+	// the translucent fills blended over the background
+	over := func(c color.RGBA) color.RGBA {
+		f := fillColor(c)
+		return color.RGBA{f.R, f.G, f.B, 255}
+	}
+	tests := []struct {
+		x, y int
+		want color.RGBA
+	}{
+		{20, 24, over(color.RGBA{127, 230, 127, 255})},
+		{60, 15, over(color.RGBA{230, 178, 178, 255})},
+		{40, 20, black},
+		{5, 35, black},
+	}
+	for _, tt := range tests {
+		if got := img.RGBAAt(tt.x, tt.y); got != tt.want {
+			t.Errorf("pixel (%d, %d) is %v, expected %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+// SVG draws to an SVG document
+type SVG struct {
+	width, height int
+	body          bytes.Buffer
+}
+
+func NewSVG(width, height int) *SVG {
+	return &SVG{width: width, height: height}
+}
+
+// Returns the SVG document of the current state of the world
+func RenderSVG(width, height int, background color.RGBA, flags phygo.DebugDrawFlags) string {
+	s := NewSVG(width, height)
+	s.Clear(background)
+	phygo.DrawDebug(s, flags)
+	return s.String()
+}
+
+func rgb(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.RGBA) float32 {
+	return float32(c.A) / 255
+}
+
+// Removes everything drawn and fills the document with c
+func (s *SVG) Clear(c color.RGBA) {
+	s.body.Reset()
+	fmt.Fprintf(&s.body, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\" fill-opacity=\"%.3g\"/>\n", rgb(c), opacity(c))
+}
+
+func (s *SVG) DrawPolygon(vertices []phygo.Vector, c color.RGBA) {
+	s.body.WriteString("<polygon points=\"")
+	for i, v := range vertices {
+		if i > 0 {
+			s.body.WriteByte(' ')
+		}
+		fmt.Fprintf(&s.body, "%.2f,%.2f", v.X, v.Y)
+	}
+	fmt.Fprintf(&s.body, "\" fill=\"%s\" fill-opacity=\"%.3g\" stroke=\"%s\" stroke-opacity=\"%.3g\"/>\n",
+		rgb(c), opacity(c)/2, rgb(c), opacity(c))
+}
+
+func (s *SVG) DrawCircle(center phygo.Vector, radius float32, c color.RGBA) {
+	fmt.Fprintf(&s.body, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%.2f\" fill=\"%s\" fill-opacity=\"%.3g\" stroke=\"%s\" stroke-opacity=\"%.3g\"/>\n",
+		center.X, center.Y, radius, rgb(c), opacity(c)/2, rgb(c), opacity(c))
+}
+
+func (s *SVG) DrawSegment(a, b phygo.Vector, c color.RGBA) {
+	fmt.Fprintf(&s.body, "<line x1=\"%.2f\" y1=\"%.2f\" x2=\"%.2f\" y2=\"%.2f\" stroke=\"%s\" stroke-opacity=\"%.3g\"/>\n",
+		a.X, a.Y, b.X, b.Y, rgb(c), opacity(c))
+}
+
+func (s *SVG) DrawPoint(p phygo.Vector, size float32, c color.RGBA) {
+	fmt.Fprintf(&s.body, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"%s\" fill-opacity=\"%.3g\"/>\n",
+		p.X-size/2, p.Y-size/2, size, size, rgb(c), opacity(c))
+}
+
+func (s *SVG) DrawTransform(pos phygo.Vector, rotation float32) {
+	xAxis, yAxis := axes(pos, rotation)
+	s.DrawSegment(pos, xAxis, xAxisColor)
+	s.DrawSegment(pos, yAxis, yAxisColor)
+}
+
+func (s *SVG) String() string {
+	var buf bytes.Buffer
+	s.WriteTo(&buf)
+	return buf.String()
+}
+
+func (s *SVG) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		s.width, s.height, s.width, s.height)
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	n, err = w.Write(s.body.Bytes())
+	written += int64(n)
+	if err != nil {
+		return written, err
+	}
+	n, err = io.WriteString(w, "</svg>\n")
+	return written + int64(n), err
+}
@@ -0,0 +1,42 @@
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+func TestRenderSVG(t *testing.T) {
+	smallWorld(t)
+	doc := RenderSVG(80, 40, black, phygo.DrawShapes)
+
+	// the document is well formed and holds the background, the box and the ball
+	counts := map[string]int{}
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%v in:\n%s", err, doc)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			counts[start.Name.Local]++
+		}
+	}
+	want := map[string]int{"svg": 1, "rect": 1, "polygon": 1, "circle": 1, "line": 1}
+	for name, n := range want {
+		if counts[name] != n {
+			t.Errorf("%d %s elements, expected %d in:\n%s", counts[name], name, n, doc)
+		}
+	}
+	for _, s := range []string{`width="80" height="40"`, `cx="60.00" cy="20.00" r="10.00"`, `points="10.00,30.00 30.00,30.00 30.00,10.00 10.00,10.00"`} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("missing %s in:\n%s", s, doc)
+		}
+	}
+}
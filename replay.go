@@ -0,0 +1,240 @@
+package phygo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var replayMagic = [4]byte{'P', 'H', 'Y', 'R'}
+
+// Replay holds a recorded session: the state when recording started and,
+// for every UpdatePhysics call, the changes made to the world since the
+// previous one, the time passed and the resulting state hashes.
+type Replay struct {
+	initial []byte
+	frames  []replayFrame
+}
+
+type replayFrame struct {
+	// encoded SnapshotDelta of the changes made before the update, nil when there were none
+	delta  []byte
+	dt     float32
+	hash   uint64
+	bodies []BodyHash
+}
+
+// ReplayMismatch is returned by PlayReplay when the replayed state differs from the recorded one
+type ReplayMismatch struct {
+	Frame    int
+	Expected uint64
+	Got      uint64
+	// ids of the bodies whose state differs, or that are missing on either side
+	Bodies []int
+}
+
+func (m *ReplayMismatch) Error() string {
+	return fmt.Sprintf("phygo: replay diverged at frame %d (hash %016x, expected %016x), bodies %v", m.Frame, m.Got, m.Expected, m.Bodies)
+}
+
+var (
+	recording *Replay
+	// the state after the last update, changes are diffed against it
+	recordBase    *Snapshot
+	recordCurrent *Snapshot
+	recordDelta   *SnapshotDelta
+)
+
+// Starts recording every change made to the world (bodies created or removed,
+// forces, velocity and position writes, settings) and every UpdatePhysics call.
+// Changes are detected by comparing the world with its state after the previous
+// update, so fields written directly are recorded too.
+func StartRecording() *Replay {
+	recordBase = TakeSnapshot(recordBase)
+	initial, _ := recordBase.MarshalBinary()
+	recording = &Replay{initial: initial}
+	return recording
+}
+
+// Stops recording and returns the replay, nil when nothing was being recorded
+func StopRecording() *Replay {
+	r := recording
+	recording = nil
+	return r
+}
+
+func IsRecording() bool {
+	return recording != nil
+}
+
+// Returns the number of recorded UpdatePhysics calls
+func (r *Replay) GetFrameCount() int {
+	return len(r.frames)
+}
+
+// compares the simulated state exactly. The transformed vertices and the aabb
+// are caches rebuilt from the position and rotation when needed, so reading
+// them between updates doesn't count as a change.
+func exactBodyChanged(a, b *Body) bool {
+	c := *a
+	c.vertices, c.transformUpdateRequired = b.vertices, b.transformUpdateRequired
+	c.aabb, c.aabbUpdateRequired = b.aabb, b.aabbUpdateRequired
+	c.islandIndex = b.islandIndex
+	return c != *b
+}
+
+// records the changes made since the last update
+func recordBeforeUpdate(dt float32) {
+	recordCurrent = TakeSnapshot(recordCurrent)
+	d := diffSnapshots(recordBase, recordCurrent, exactBodyChanged, recordDelta)
+	recordDelta = d
+
+	frame := replayFrame{dt: dt}
+	if len(d.bodies) > 0 || len(d.removed) > 0 || len(d.order) > 0 || d.joints != nil || d.world != recordBase.world {
+		frame.delta, _ = d.MarshalBinary()
+	}
+	recording.frames = append(recording.frames, frame)
+}
+
+func recordAfterUpdate() {
+	frame := &recording.frames[len(recording.frames)-1]
+	frame.hash = StateHash()
+	frame.bodies = StateHashPerBody(nil)
+	recordBase = TakeSnapshot(recordBase)
+}
+
+// Replaces the world with the replay's initial state and replays every frame,
+// calling onFrame (when not nil) after each one. Returns a *ReplayMismatch as
+// soon as the state differs from the recorded one.
+func PlayReplay(r *Replay, onFrame func(frame int)) error {
+	if recording != nil {
+		return errors.New("phygo: can't play a replay while recording")
+	}
+
+	var initial Snapshot
+	if err := initial.UnmarshalBinary(r.initial); err != nil {
+		return err
+	}
+	RestoreSnapshot(&initial)
+
+	var current, next *Snapshot
+	var delta SnapshotDelta
+	var hashes []BodyHash
+	for i, frame := range r.frames {
+		if frame.delta != nil {
+			if err := delta.UnmarshalBinary(frame.delta); err != nil {
+				return fmt.Errorf("phygo: replay frame %d: %w", i, err)
+			}
+			current = TakeSnapshot(current)
+			var err error
+			if next, err = ApplySnapshotDelta(current, &delta, next); err != nil {
+				return fmt.Errorf("phygo: replay frame %d: %w", i, err)
+			}
+			RestoreSnapshot(next)
+		}
+
+		UpdatePhysics(frame.dt)
+
+		if hash := StateHash(); hash != frame.hash {
+			hashes = StateHashPerBody(hashes[:0])
+			return &ReplayMismatch{
+				Frame:    i,
+				Expected: frame.hash,
+				Got:      hash,
+				Bodies:   divergedBodies(frame.bodies, hashes),
+			}
+		}
+		if onFrame != nil {
+			onFrame(i)
+		}
+	}
+	return nil
+}
+
+func divergedBodies(expected, got []BodyHash) []int {
+	var ids []int
+	for _, e := range expected {
+		found := false
+		for _, g := range got {
+			if g.Id == e.Id {
+				found = true
+				if g.Hash != e.Hash {
+					ids = append(ids, e.Id)
+				}
+				break
+			}
+		}
+		if !found {
+			ids = append(ids, e.Id)
+		}
+	}
+	for _, g := range got {
+		found := false
+		for _, e := range expected {
+			if g.Id == e.Id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ids = append(ids, g.Id)
+		}
+	}
+	return ids
+}
+
+func (r *Replay) MarshalBinary() ([]byte, error) {
+	var w binWriter
+	w.header(replayMagic)
+
+	w.u32(uint32(len(r.initial)))
+	w.buf = append(w.buf, r.initial...)
+
+	w.u32(uint32(len(r.frames)))
+	for _, f := range r.frames {
+		w.f32(f.dt)
+		w.u64(f.hash)
+		w.bool(f.delta != nil)
+		if f.delta != nil {
+			w.u32(uint32(len(f.delta)))
+			w.buf = append(w.buf, f.delta...)
+		}
+		w.u32(uint32(len(f.bodies)))
+		for _, b := range f.bodies {
+			w.i32(int32(b.Id))
+			w.u64(b.Hash)
+		}
+	}
+	return w.buf, nil
+}
+
+func (r *Replay) UnmarshalBinary(data []byte) error {
+	br := binReader{buf: data}
+	if err := br.header(replayMagic); err != nil {
+		return err
+	}
+
+	var decoded Replay
+	decoded.initial = append([]byte{}, br.next(int(br.u32()))...)
+
+	count := int(br.u32())
+	for i := 0; i < count && br.err == nil; i++ {
+		f := replayFrame{dt: br.f32(), hash: br.u64()}
+		if br.bool() {
+			f.delta = append([]byte{}, br.next(int(br.u32()))...)
+		}
+		bodyCount := int(br.u32())
+		if br.err == nil && bodyCount > maxBodies {
+			return fmt.Errorf("phygo: replay frame %d has %d bodies, at most %d are supported", i, bodyCount, maxBodies)
+		}
+		for j := 0; j < bodyCount && br.err == nil; j++ {
+			f.bodies = append(f.bodies, BodyHash{int(br.i32()), br.u64()})
+		}
+		decoded.frames = append(decoded.frames, f)
+	}
+
+	if br.err != nil {
+		return br.err
+	}
+	*r = decoded
+	return nil
+}
@@ -0,0 +1,67 @@
+package phygo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+
+	StartRecording()
+	runHashes(200)
+	replay := StopRecording()
+
+	data, err := replay.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Replay
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	Close()
+	if err := PlayReplay(&decoded, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// a changed frame is reported as a mismatch
+	decoded.frames[100].hash++
+	var mismatch *ReplayMismatch
+	if err := PlayReplay(&decoded, nil); !errors.As(err, &mismatch) || mismatch.Frame != 100 {
+		t.Errorf("got %v, expected a mismatch at frame 100", err)
+	}
+}
+
+// reading the vertices between updates refreshes caches but records nothing,
+// writing a velocity records the body
+func TestReplayRecordsChanges(t *testing.T) {
+	resetWorld(t)
+	SetDeterministic(true)
+	setupMixedScene(t)
+	ball := GetBodies()[GetBodiesCount()-1]
+
+	replay := StartRecording()
+	for i := 0; i < 20; i++ {
+		for _, b := range GetBodies() {
+			b.GetVertices()
+		}
+		if i == 10 {
+			ball.Velocity = NewVector(0, -0.05)
+		}
+		UpdatePhysics(1.0 / 60)
+	}
+	StopRecording()
+
+	for i, frame := range replay.frames {
+		if recorded := frame.delta != nil; recorded != (i == 10) {
+			t.Errorf("frame %d recorded a delta: %v", i, recorded)
+		}
+	}
+	if err := PlayReplay(replay, nil); err != nil {
+		t.Fatal(err)
+	}
+}
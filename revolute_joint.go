@@ -0,0 +1,123 @@
+package phygo
+
+// RevoluteJoint pins two bodies together at an anchor point, leaving the
+// relative rotation free. It can be driven by a motor.
+type RevoluteJoint struct {
+	jointBase
+	localAnchorA   Vector
+	localAnchorB   Vector
+	referenceAngle float32
+
+	EnableMotor    bool
+	MotorSpeed     float32 // radians per unit of time, same as AngularVelocity
+	MaxMotorTorque float32
+
+	rA, rB       Vector
+	mass         mat22
+	bias         Vector
+	motorMass    float32
+	motorImpulse float32
+}
+
+// anchor is the world point in pixels the bodies are pinned at
+func CreateRevoluteJoint(bodyA, bodyB *Body, anchor Vector) (*RevoluteJoint, error) {
+	if err := checkJointInput(bodyA, bodyB); err != nil {
+		return nil, err
+	}
+	anchor = VectorMul(anchor, 1/float32(ppu))
+
+	newJoint := &RevoluteJoint{
+		jointBase:      jointBase{bodyA: bodyA, bodyB: bodyB},
+		localAnchorA:   localPoint(bodyA, anchor),
+		localAnchorB:   localPoint(bodyB, anchor),
+		referenceAngle: bodyB.Rotation - bodyA.Rotation,
+	}
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func (j *RevoluteJoint) GetType() JointType {
+	return RevoluteJointType
+}
+
+func (j *RevoluteJoint) GetAnchorA() Vector {
+	return worldAnchor(j.bodyA, j.localAnchorA)
+}
+
+func (j *RevoluteJoint) GetAnchorB() Vector {
+	return worldAnchor(j.bodyB, j.localAnchorB)
+}
+
+func (j *RevoluteJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*RevoluteJoint)
+	if !ok {
+		d = &RevoluteJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *RevoluteJoint) hashState(h uint64) uint64 {
+	h = hashBool(h, j.EnableMotor)
+	h = hashFloat(h, j.MotorSpeed)
+	h = hashFloat(h, j.MaxMotorTorque)
+	return hashFloat(h, j.motorImpulse)
+}
+
+// Returns the relative rotation of body B to body A since the joint was created
+func (j *RevoluteJoint) GetJointAngle() float32 {
+	return j.bodyB.Rotation - j.bodyA.Rotation - j.referenceAngle
+}
+
+func (j *RevoluteJoint) prepare(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	h := dt * ppu
+
+	j.rA = rotateVector(j.localAnchorA, bodyA.Rotation)
+	j.rB = rotateVector(j.localAnchorB, bodyB.Rotation)
+
+	mA, mB := bodyA.invMass, bodyB.invMass
+	iA, iB := bodyA.getInvInertia(), bodyB.getInvInertia()
+
+	var K mat22
+	K.ex.X = mA + mB + float32(iA*j.rA.Y*j.rA.Y) + float32(iB*j.rB.Y*j.rB.Y)
+	K.ex.Y = float32(-iA*j.rA.X*j.rA.Y) - float32(iB*j.rB.X*j.rB.Y)
+	K.ey.X = K.ex.Y
+	K.ey.Y = mA + mB + float32(iA*j.rA.X*j.rA.X) + float32(iB*j.rB.X*j.rB.X)
+	j.mass = K.inverse()
+
+	c := VectorSubtract(VectorAdd(bodyB.position, j.rB), VectorAdd(bodyA.position, j.rA))
+	j.bias = VectorMul(c, jointBaumgarte/h)
+
+	j.motorMass = iA + iB
+	if j.motorMass > 0 {
+		j.motorMass = 1 / j.motorMass
+	}
+	j.motorImpulse = 0
+}
+
+func (j *RevoluteJoint) solve(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+
+	if j.EnableMotor {
+		cdot := bodyB.AngularVelocity - bodyA.AngularVelocity - j.MotorSpeed
+		impulse := -j.motorMass * cdot
+		oldImpulse := j.motorImpulse
+		maxImpulse := j.MaxMotorTorque * dt
+		j.motorImpulse = ClampFloat(j.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse = j.motorImpulse - oldImpulse
+
+		applyJointImpulse(bodyA, VectorZero(), -impulse)
+		applyJointImpulse(bodyB, VectorZero(), impulse)
+	}
+
+	vA := VectorAdd(bodyA.Velocity, crossSV(bodyA.AngularVelocity, j.rA))
+	vB := VectorAdd(bodyB.Velocity, crossSV(bodyB.AngularVelocity, j.rB))
+	cdot := VectorSubtract(vB, vA)
+
+	impulse := j.mass.mulV(VectorMul(VectorAdd(cdot, j.bias), -1))
+
+	applyJointImpulse(bodyA, VectorMul(impulse, -1), -VectorCrossProduct(j.rA, impulse))
+	applyJointImpulse(bodyB, impulse, VectorCrossProduct(j.rB, impulse))
+}
@@ -0,0 +1,551 @@
+package phygo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// version of the scene format written by SaveScene
+const sceneVersion = 1
+
+// positions, sizes and anchors are in pixels like the rest of the public API,
+// velocities and speeds are stored as they are in the Body fields
+type sceneVector struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type sceneFile struct {
+	Version          int          `json:"version"`
+	Gravity          sceneVector  `json:"gravity"` // as passed to SetGravity
+	Iterations       int          `json:"iterations"`
+	FixedTimestep    float32      `json:"fixedTimestep,omitempty"`
+	MaxStepsPerFrame int          `json:"maxStepsPerFrame,omitempty"`
+	Deterministic    bool         `json:"deterministic,omitempty"`
+	Sleep            *sceneSleep  `json:"sleep,omitempty"` // older scenes load with sleeping off
+	Bodies           []sceneBody  `json:"bodies"`
+	Joints           []sceneJoint `json:"joints,omitempty"`
+}
+
+type sceneSleep struct {
+	Enabled          bool    `json:"enabled"`
+	LinearTolerance  float32 `json:"linearTolerance"`
+	AngularTolerance float32 `json:"angularTolerance"`
+	Time             float32 `json:"time"`
+}
+
+type sceneBody struct {
+	Id              int         `json:"id"`
+	Type            string      `json:"type"`
+	Shape           string      `json:"shape"`
+	Radius          float32     `json:"radius,omitempty"`
+	Width           float32     `json:"width,omitempty"`
+	Height          float32     `json:"height,omitempty"`
+	Density         float32     `json:"density"`
+	Position        sceneVector `json:"position"`
+	Rotation        float32     `json:"rotation"`
+	Velocity        sceneVector `json:"velocity"` // engine units like Body.Velocity, not pixels
+	AngularVelocity float32     `json:"angularVelocity"`
+
+	Restitution     float32 `json:"restitution"`
+	StaticFriction  float32 `json:"staticFriction"`
+	DynamicFriction float32 `json:"dynamicFriction"`
+	LinearDamping   float32 `json:"linearDamping,omitempty"`
+	AngularDamping  float32 `json:"angularDamping,omitempty"`
+	GravityScale    float32 `json:"gravityScale"`
+	MaxLinearSpeed  float32 `json:"maxLinearSpeed,omitempty"` // same units as velocity
+	MaxAngularSpeed float32 `json:"maxAngularSpeed,omitempty"`
+
+	RotationDisabled bool `json:"rotationDisabled"`
+	UseGravity       bool `json:"useGravity"`
+	AllowSleep       bool `json:"allowSleep"`
+	Awake            bool `json:"awake"`
+}
+
+// anchors and axes are stored in the local space of their body so joints load exactly
+type sceneJoint struct {
+	Type             string       `json:"type"`
+	BodyA            *int         `json:"bodyA,omitempty"`
+	BodyB            *int         `json:"bodyB,omitempty"`
+	CollideConnected bool         `json:"collideConnected"`
+	LocalAnchorA     *sceneVector `json:"localAnchorA,omitempty"`
+	LocalAnchorB     *sceneVector `json:"localAnchorB,omitempty"`
+	LocalAxisA       *sceneVector `json:"localAxisA,omitempty"`
+	ReferenceAngle   float32      `json:"referenceAngle,omitempty"`
+
+	// mouse joint
+	Target   *sceneVector `json:"target,omitempty"`
+	MaxForce float32      `json:"maxForce,omitempty"`
+	// mouse and wheel joint springs
+	Frequency    float32 `json:"frequency,omitempty"`
+	DampingRatio float32 `json:"dampingRatio,omitempty"`
+
+	// motor speeds, forces and torques use the units of the joint fields
+	EnableMotor    bool    `json:"enableMotor,omitempty"`
+	MotorSpeed     float32 `json:"motorSpeed,omitempty"`
+	MaxMotorTorque float32 `json:"maxMotorTorque,omitempty"`
+	MaxMotorForce  float32 `json:"maxMotorForce,omitempty"`
+
+	// pulley joint
+	GroundAnchorA *sceneVector `json:"groundAnchorA,omitempty"`
+	GroundAnchorB *sceneVector `json:"groundAnchorB,omitempty"`
+	// pulley and gear joints, the constant is the rope length in pixels for
+	// pulleys. For gears it's the sum of the coupled coordinates, radians for
+	// revolute joints and engine units (pixels / 50) for prismatic ones
+	Ratio    float32  `json:"ratio,omitempty"`
+	Constant *float32 `json:"constant,omitempty"`
+	// gear joint, indices of earlier entries in the joints list
+	Joint1 *int `json:"joint1,omitempty"`
+	Joint2 *int `json:"joint2,omitempty"`
+}
+
+var bodyTypeNames = [...]string{StaticBody: "static", KinematicBody: "kinematic", DynamicBody: "dynamic"}
+var shapeTypeNames = [...]string{CircleShape: "circle", RectangleShape: "rectangle"}
+var jointTypeNames = [...]string{
+	MouseJointType:     "mouse",
+	RevoluteJointType:  "revolute",
+	PrismaticJointType: "prismatic",
+	WheelJointType:     "wheel",
+	PulleyJointType:    "pulley",
+	GearJointType:      "gear",
+}
+
+func toSceneVector(v Vector) sceneVector {
+	return sceneVector{v.X, v.Y}
+}
+
+// converts a vector in units to pixels for the scene
+func toScenePixels(v Vector) *sceneVector {
+	return &sceneVector{v.X * ppu, v.Y * ppu}
+}
+
+func (v sceneVector) units() Vector {
+	return NewVector(v.X/ppu, v.Y/ppu)
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Writes the world settings, bodies and joints as JSON
+func SaveScene(w io.Writer) error {
+	scene := sceneFile{
+		Version:          sceneVersion,
+		Gravity:          toSceneVector(gravity),
+		Iterations:       iterations,
+		FixedTimestep:    fixedTimestep,
+		MaxStepsPerFrame: maxStepsPerFrame,
+		Deterministic:    deterministic,
+		Sleep: &sceneSleep{
+			Enabled:          sleepEnabled,
+			LinearTolerance:  linearSleepTolerance,
+			AngularTolerance: angularSleepTolerance,
+			Time:             timeToSleep,
+		},
+		Bodies: make([]sceneBody, 0, bodyCount),
+	}
+
+	for _, b := range bodies[:bodyCount] {
+		scene.Bodies = append(scene.Bodies, sceneBody{
+			Id:               b.Id,
+			Type:             bodyTypeNames[b.bodyType],
+			Shape:            shapeTypeNames[b.ShapeType],
+			Radius:           b.GetRadius(),
+			Width:            b.GetWidth(),
+			Height:           b.GetHeight(),
+			Density:          b.mass / b.area,
+			Position:         toSceneVector(b.GetPos()),
+			Rotation:         b.Rotation,
+			Velocity:         toSceneVector(b.Velocity),
+			AngularVelocity:  b.AngularVelocity,
+			Restitution:      b.restitution,
+			StaticFriction:   b.staticFriction,
+			DynamicFriction:  b.dynamicFriction,
+			LinearDamping:    b.LinearDamping,
+			AngularDamping:   b.AngularDamping,
+			GravityScale:     b.GravityScale,
+			MaxLinearSpeed:   b.MaxLinearSpeed,
+			MaxAngularSpeed:  b.MaxAngularSpeed,
+			RotationDisabled: b.RotationDisabled,
+			UseGravity:       b.UseGravity,
+			AllowSleep:       b.AllowSleep,
+			Awake:            b.awake,
+		})
+	}
+
+	for _, j := range joints[:jointCount] {
+		scene.Joints = append(scene.Joints, saveJoint(j))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(scene)
+}
+
+func saveJoint(j Joint) sceneJoint {
+	sj := sceneJoint{Type: jointTypeNames[j.GetType()]}
+	if a := j.GetBodyA(); a != nil {
+		sj.BodyA = &a.Id
+	}
+	if b := j.GetBodyB(); b != nil {
+		sj.BodyB = &b.Id
+	}
+
+	switch j := j.(type) {
+	case *MouseJoint:
+		sj.CollideConnected = j.CollideConnected
+		sj.LocalAnchorB = toScenePixels(j.localAnchor)
+		sj.Target = toScenePixels(j.target)
+		sj.MaxForce = j.MaxForce
+		sj.Frequency = j.Frequency
+		sj.DampingRatio = j.DampingRatio
+	case *RevoluteJoint:
+		sj.CollideConnected = j.CollideConnected
+		sj.LocalAnchorA = toScenePixels(j.localAnchorA)
+		sj.LocalAnchorB = toScenePixels(j.localAnchorB)
+		sj.ReferenceAngle = j.referenceAngle
+		sj.EnableMotor = j.EnableMotor
+		sj.MotorSpeed = j.MotorSpeed
+		sj.MaxMotorTorque = j.MaxMotorTorque
+	case *PrismaticJoint:
+		axis := toSceneVector(j.localAxisA)
+		sj.CollideConnected = j.CollideConnected
+		sj.LocalAnchorA = toScenePixels(j.localAnchorA)
+		sj.LocalAnchorB = toScenePixels(j.localAnchorB)
+		sj.LocalAxisA = &axis
+		sj.ReferenceAngle = j.referenceAngle
+		sj.EnableMotor = j.EnableMotor
+		sj.MotorSpeed = j.MotorSpeed
+		sj.MaxMotorForce = j.MaxMotorForce
+	case *WheelJoint:
+		axis := toSceneVector(j.localAxisA)
+		sj.CollideConnected = j.CollideConnected
+		sj.LocalAnchorA = toScenePixels(j.localAnchorA)
+		sj.LocalAnchorB = toScenePixels(j.localAnchorB)
+		sj.LocalAxisA = &axis
+		sj.Frequency = j.Frequency
+		sj.DampingRatio = j.DampingRatio
+		sj.EnableMotor = j.EnableMotor
+		sj.MotorSpeed = j.MotorSpeed
+		sj.MaxMotorTorque = j.MaxMotorTorque
+	case *PulleyJoint:
+		constant := j.constant * ppu
+		sj.CollideConnected = j.CollideConnected
+		sj.LocalAnchorA = toScenePixels(j.localAnchorA)
+		sj.LocalAnchorB = toScenePixels(j.localAnchorB)
+		sj.GroundAnchorA = toScenePixels(j.groundAnchorA)
+		sj.GroundAnchorB = toScenePixels(j.groundAnchorB)
+		sj.Ratio = j.ratio
+		sj.Constant = &constant
+	case *GearJoint:
+		joint1, joint2 := jointIndex(j.joint1), jointIndex(j.joint2)
+		sj.CollideConnected = j.CollideConnected
+		sj.Ratio = j.ratio
+		sj.Constant = &j.constant
+		sj.Joint1 = &joint1
+		sj.Joint2 = &joint2
+	}
+	return sj
+}
+
+func jointIndex(j Joint) int {
+	for i, other := range joints[:jointCount] {
+		if other == j {
+			return i
+		}
+	}
+	return -1
+}
+
+// Replaces the current world with the scene read from r. The world is left
+// untouched when the scene is invalid, the error names the offending entry.
+// Bodies keep the ids they were saved with.
+func LoadScene(r io.Reader) error {
+	var scene sceneFile
+	if err := json.NewDecoder(r).Decode(&scene); err != nil {
+		return fmt.Errorf("scene: %w", err)
+	}
+	if err := validateScene(&scene); err != nil {
+		return err
+	}
+
+	// validateScene ran every check loadBody can fail, nothing below returns
+	// an error once the world is closed
+	Close()
+	SetGravity(scene.Gravity.X, scene.Gravity.Y)
+	if scene.Iterations > 0 {
+		SetIteration(scene.Iterations)
+	}
+	if scene.MaxStepsPerFrame == 0 {
+		scene.MaxStepsPerFrame = defaultMaxStepsPerFrame
+	}
+	SetFixedTimestep(scene.FixedTimestep, scene.MaxStepsPerFrame)
+	SetDeterministic(scene.Deterministic)
+	if scene.Sleep == nil {
+		scene.Sleep = &sceneSleep{false, defaultLinearSleepTolerance, defaultAngularSleepTolerance, defaultTimeToSleep}
+	}
+	SetSleepEnabled(scene.Sleep.Enabled)
+	SetSleepThresholds(scene.Sleep.LinearTolerance, scene.Sleep.AngularTolerance, scene.Sleep.Time)
+
+	created := make([]*Body, len(scene.Bodies))
+	for i, sb := range scene.Bodies {
+		b, err := loadBody(sb)
+		if err != nil {
+			return fmt.Errorf("scene: bodies[%d]: %w", i, err)
+		}
+		created[i] = b
+	}
+	// the ids are unique, set after every body is created so getId doesn't
+	// hand out an id a later body claims
+	for i, sb := range scene.Bodies {
+		created[i].Id = sb.Id
+	}
+
+	findBody := func(id *int) *Body {
+		if id == nil {
+			return nil
+		}
+		for i, sb := range scene.Bodies {
+			if sb.Id == *id {
+				return created[i]
+			}
+		}
+		return nil
+	}
+
+	loaded := make([]Joint, len(scene.Joints))
+	for i, sj := range scene.Joints {
+		loaded[i] = loadJoint(sj, findBody(sj.BodyA), findBody(sj.BodyB), loaded)
+		addJoint(loaded[i])
+	}
+
+	return nil
+}
+
+func loadBody(sb sceneBody) (*Body, error) {
+	bodyType := BodyType(indexOf(bodyTypeNames[:], sb.Type))
+	pos := NewVector(sb.Position.X, sb.Position.Y)
+
+	var b *Body
+	var err error
+	if sb.Shape == shapeTypeNames[CircleShape] {
+		b, err = createCircle(pos, sb.Radius, sb.Density, bodyType)
+	} else {
+		b, err = createRectangle(pos, sb.Width, sb.Height, sb.Density, bodyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.RotateTo(sb.Rotation)
+	b.Velocity = NewVector(sb.Velocity.X, sb.Velocity.Y)
+	b.AngularVelocity = sb.AngularVelocity
+
+	b.SetRestitution(sb.Restitution)
+	b.SetStaticFriction(sb.StaticFriction)
+	b.SetDynamicFriction(sb.DynamicFriction)
+	b.LinearDamping = sb.LinearDamping
+	b.AngularDamping = sb.AngularDamping
+	b.GravityScale = sb.GravityScale
+	b.MaxLinearSpeed = sb.MaxLinearSpeed
+	b.MaxAngularSpeed = sb.MaxAngularSpeed
+	b.RotationDisabled = sb.RotationDisabled
+	b.UseGravity = sb.UseGravity
+	b.AllowSleep = sb.AllowSleep
+	b.awake = sb.Awake && bodyType != StaticBody
+
+	return b, nil
+}
+
+func loadJoint(sj sceneJoint, bodyA, bodyB *Body, loaded []Joint) Joint {
+	base := jointBase{bodyA: bodyA, bodyB: bodyB, CollideConnected: sj.CollideConnected}
+
+	switch JointType(indexOf(jointTypeNames[:], sj.Type)) {
+	case MouseJointType:
+		return &MouseJoint{
+			jointBase:    base,
+			localAnchor:  sj.LocalAnchorB.units(),
+			target:       sj.Target.units(),
+			MaxForce:     sj.MaxForce,
+			Frequency:    sj.Frequency,
+			DampingRatio: sj.DampingRatio,
+		}
+	case RevoluteJointType:
+		return &RevoluteJoint{
+			jointBase:      base,
+			localAnchorA:   sj.LocalAnchorA.units(),
+			localAnchorB:   sj.LocalAnchorB.units(),
+			referenceAngle: sj.ReferenceAngle,
+			EnableMotor:    sj.EnableMotor,
+			MotorSpeed:     sj.MotorSpeed,
+			MaxMotorTorque: sj.MaxMotorTorque,
+		}
+	case PrismaticJointType:
+		return &PrismaticJoint{
+			jointBase:      base,
+			localAnchorA:   sj.LocalAnchorA.units(),
+			localAnchorB:   sj.LocalAnchorB.units(),
+			localAxisA:     VectorNormalize(NewVector(sj.LocalAxisA.X, sj.LocalAxisA.Y)),
+			referenceAngle: sj.ReferenceAngle,
+			EnableMotor:    sj.EnableMotor,
+			MotorSpeed:     sj.MotorSpeed,
+			MaxMotorForce:  sj.MaxMotorForce,
+		}
+	case WheelJointType:
+		return &WheelJoint{
+			jointBase:      base,
+			localAnchorA:   sj.LocalAnchorA.units(),
+			localAnchorB:   sj.LocalAnchorB.units(),
+			localAxisA:     VectorNormalize(NewVector(sj.LocalAxisA.X, sj.LocalAxisA.Y)),
+			Frequency:      sj.Frequency,
+			DampingRatio:   sj.DampingRatio,
+			EnableMotor:    sj.EnableMotor,
+			MotorSpeed:     sj.MotorSpeed,
+			MaxMotorTorque: sj.MaxMotorTorque,
+		}
+	case PulleyJointType:
+		j := &PulleyJoint{
+			jointBase:     base,
+			groundAnchorA: sj.GroundAnchorA.units(),
+			groundAnchorB: sj.GroundAnchorB.units(),
+			localAnchorA:  sj.LocalAnchorA.units(),
+			localAnchorB:  sj.LocalAnchorB.units(),
+			ratio:         sj.Ratio,
+		}
+		if sj.Constant != nil {
+			j.constant = *sj.Constant / ppu
+		} else {
+			j.constant = j.GetLengthA()/ppu + j.ratio*j.GetLengthB()/ppu
+		}
+		return j
+	default:
+		joint1, joint2 := loaded[*sj.Joint1], loaded[*sj.Joint2]
+		j := &GearJoint{
+			jointBase: jointBase{bodyA: joint1.GetBodyB(), bodyB: joint2.GetBodyB(), CollideConnected: sj.CollideConnected},
+			joint1:    joint1,
+			joint2:    joint2,
+			bodyC:     joint1.GetBodyA(),
+			bodyD:     joint2.GetBodyA(),
+			ratio:     sj.Ratio,
+		}
+		if sj.Constant != nil {
+			j.constant = *sj.Constant
+		} else {
+			j.constant = gearCoordinate(joint1) + j.ratio*gearCoordinate(joint2)
+		}
+		return j
+	}
+}
+
+func validateScene(scene *sceneFile) error {
+	if scene.Version < 1 || scene.Version > sceneVersion {
+		return fmt.Errorf("scene: unsupported version %d", scene.Version)
+	}
+	if len(scene.Bodies) > maxBodies {
+		return fmt.Errorf("scene: %d bodies, at most %d are supported", len(scene.Bodies), maxBodies)
+	}
+	if len(scene.Joints) > maxJoints {
+		return fmt.Errorf("scene: %d joints, at most %d are supported", len(scene.Joints), maxJoints)
+	}
+
+	for i, sb := range scene.Bodies {
+		if sb.Id < 0 {
+			return fmt.Errorf("scene: bodies[%d]: negative id %d", i, sb.Id)
+		}
+		for _, other := range scene.Bodies[:i] {
+			if other.Id == sb.Id {
+				return fmt.Errorf("scene: bodies[%d]: duplicate id %d", i, sb.Id)
+			}
+		}
+
+		bodyType := indexOf(bodyTypeNames[:], sb.Type)
+		if bodyType < 0 {
+			return fmt.Errorf("scene: bodies[%d]: unknown type %q", i, sb.Type)
+		}
+		var err error
+		switch sb.Shape {
+		case shapeTypeNames[CircleShape]:
+			err = checkCircle(sb.Radius)
+		case shapeTypeNames[RectangleShape]:
+			err = checkRectangle(sb.Width, sb.Height)
+		default:
+			return fmt.Errorf("scene: bodies[%d]: unknown shape %q", i, sb.Shape)
+		}
+		if err == nil {
+			err = checkBodyValues(NewVector(sb.Position.X, sb.Position.Y), sb.Density, BodyType(bodyType))
+		}
+		if err != nil {
+			return fmt.Errorf("scene: bodies[%d]: %w", i, err)
+		}
+	}
+
+	hasBody := func(id *int) bool {
+		for _, sb := range scene.Bodies {
+			if sb.Id == *id {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, sj := range scene.Joints {
+		jointType := indexOf(jointTypeNames[:], sj.Type)
+		if jointType < 0 {
+			return fmt.Errorf("scene: joints[%d]: unknown type %q", i, sj.Type)
+		}
+
+		if JointType(jointType) == GearJointType {
+			if sj.Joint1 == nil || sj.Joint2 == nil {
+				return fmt.Errorf("scene: joints[%d]: gear joints need joint1 and joint2", i)
+			}
+			if *sj.Joint1 == *sj.Joint2 {
+				return fmt.Errorf("scene: joints[%d]: gear joints couple two different joints", i)
+			}
+			for _, index := range []int{*sj.Joint1, *sj.Joint2} {
+				if index < 0 || index >= i {
+					return fmt.Errorf("scene: joints[%d]: joint %d must be an earlier entry", i, index)
+				}
+				if t := indexOf(jointTypeNames[:], scene.Joints[index].Type); t != int(RevoluteJointType) && t != int(PrismaticJointType) {
+					return fmt.Errorf("scene: joints[%d]: joint %d must be revolute or prismatic", i, index)
+				}
+			}
+			continue
+		}
+
+		if sj.BodyB == nil || !hasBody(sj.BodyB) {
+			return fmt.Errorf("scene: joints[%d]: bodyB doesn't refer to a body", i)
+		}
+
+		var required []*sceneVector
+		switch JointType(jointType) {
+		case MouseJointType:
+			required = []*sceneVector{sj.LocalAnchorB, sj.Target}
+		case RevoluteJointType:
+			required = []*sceneVector{sj.LocalAnchorA, sj.LocalAnchorB}
+		case PrismaticJointType, WheelJointType:
+			required = []*sceneVector{sj.LocalAnchorA, sj.LocalAnchorB, sj.LocalAxisA}
+		case PulleyJointType:
+			required = []*sceneVector{sj.LocalAnchorA, sj.LocalAnchorB, sj.GroundAnchorA, sj.GroundAnchorB}
+			if sj.Ratio <= 0 {
+				return fmt.Errorf("scene: joints[%d]: ratio must be positive, got %v", i, sj.Ratio)
+			}
+		}
+		if JointType(jointType) != MouseJointType && (sj.BodyA == nil || !hasBody(sj.BodyA)) {
+			return fmt.Errorf("scene: joints[%d]: bodyA doesn't refer to a body", i)
+		}
+		if sj.BodyA != nil && *sj.BodyA == *sj.BodyB {
+			return fmt.Errorf("scene: joints[%d]: a joint can't connect body %d to itself", i, *sj.BodyA)
+		}
+		for _, v := range required {
+			if v == nil {
+				return fmt.Errorf("scene: joints[%d]: missing anchors or axis for a %s joint", i, sj.Type)
+			}
+		}
+	}
+	return nil
+}
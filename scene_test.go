@@ -0,0 +1,122 @@
+package phygo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSceneRoundTrip(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	runHashes(50)
+
+	var first bytes.Buffer
+	if err := SaveScene(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadScene(bytes.NewReader(first.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	var second bytes.Buffer
+	if err := SaveScene(&second); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Errorf("scene changed after loading it:\n%s\nexpected:\n%s", second.Bytes(), first.Bytes())
+	}
+}
+
+// removed bodies leave gaps in the ids, loading keeps them
+func TestSceneKeepsIds(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	RemoveBody(GetBodies()[2])
+	RemoveBody(GetBodies()[4])
+
+	var ids []int
+	for _, b := range GetBodies() {
+		ids = append(ids, b.Id)
+	}
+	var scene bytes.Buffer
+	if err := SaveScene(&scene); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadScene(&scene); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range GetBodies() {
+		if b.Id != ids[i] {
+			t.Fatalf("body %d has id %d, expected %d", i, b.Id, ids[i])
+		}
+	}
+	if b := mustBody(t)(CreateBodyCircle(NewVector(0, 0), 5, 1, false)); b.Id != 2 {
+		t.Errorf("new body got id %d, expected the free id 2", b.Id)
+	}
+}
+
+// an invalid body after valid ones leaves the world as it was
+func TestSceneLoadFailure(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	before := GetBodiesCount()
+
+	scene := `{"version": 1, "gravity": {"x": 0, "y": 1}, "iterations": 8, "bodies": [
+		{"id": 0, "type": "static", "shape": "circle", "radius": 10, "density": 0, "position": {"x": 0, "y": 0}},
+		{"id": 1, "type": "dynamic", "shape": "circle", "radius": 10, "density": 0, "position": {"x": 50, "y": 0}}
+	]}`
+	if err := LoadScene(strings.NewReader(scene)); err == nil {
+		t.Fatal("loaded a dynamic body without density")
+	}
+	if GetBodiesCount() != before || GetJointsCount() != 1 || iterations != 32 {
+		t.Errorf("the failed load changed the world to %d bodies, %d joints and %d iterations",
+			GetBodiesCount(), GetJointsCount(), iterations)
+	}
+}
+
+// the world settings are saved with the bodies
+func TestSceneSettings(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	SetDeterministic(true)
+	SetSleepEnabled(true)
+	SetSleepThresholds(0.01, 0.02, 2)
+
+	var scene bytes.Buffer
+	if err := SaveScene(&scene); err != nil {
+		t.Fatal(err)
+	}
+	SetDeterministic(false)
+	SetSleepEnabled(false)
+	SetSleepThresholds(0.005, 0.002, 0.5)
+	if err := LoadScene(&scene); err != nil {
+		t.Fatal(err)
+	}
+	if !IsDeterministic() || !sleepEnabled {
+		t.Errorf("deterministic %v and sleeping %v, expected both on", IsDeterministic(), sleepEnabled)
+	}
+	if linearSleepTolerance != 0.01 || angularSleepTolerance != 0.02 || timeToSleep != 2 {
+		t.Errorf("sleep thresholds %v, %v, %v, expected 0.01, 0.02, 2", linearSleepTolerance, angularSleepTolerance, timeToSleep)
+	}
+
+	// scenes saved without the settings load with the defaults
+	old := `{"version": 1, "gravity": {"x": 0, "y": 1}, "iterations": 8, "bodies": []}`
+	if err := LoadScene(strings.NewReader(old)); err != nil {
+		t.Fatal(err)
+	}
+	if IsDeterministic() || sleepEnabled || timeToSleep != defaultTimeToSleep {
+		t.Errorf("deterministic %v, sleeping %v after %vs, expected the defaults", IsDeterministic(), sleepEnabled, timeToSleep)
+	}
+}
+
+func TestSceneRejectsSelfJoint(t *testing.T) {
+	resetWorld(t)
+	scene := `{"version": 1, "gravity": {"x": 0, "y": 1}, "iterations": 8, "bodies": [
+		{"id": 0, "type": "dynamic", "shape": "circle", "radius": 10, "density": 1, "position": {"x": 0, "y": 0}}
+	], "joints": [
+		{"type": "revolute", "bodyA": 0, "bodyB": 0, "localAnchorA": {"x": 0, "y": 0}, "localAnchorB": {"x": 0, "y": 0}}
+	]}`
+	if err := LoadScene(strings.NewReader(scene)); err == nil || !strings.Contains(err.Error(), "itself") {
+		t.Errorf("got %v, expected a joint connecting a body to itself to be rejected", err)
+	}
+}
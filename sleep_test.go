@@ -0,0 +1,124 @@
+package phygo
+
+import "testing"
+
+// a box resting on the ground, stepped until it falls asleep
+func restingBox(t *testing.T) (ground, box *Body) {
+	t.Helper()
+	must := mustBody(t)
+	ground = must(CreateBodyRectangle(NewVector(300, 400), 600, 40, 1, true))
+	box = must(CreateBodyRectangle(NewVector(300, 370), 20, 20, 1, false))
+	stepFrames(120)
+	if box.IsAwake() {
+		t.Fatalf("box still awake at velocity %v", box.Velocity)
+	}
+	return ground, box
+}
+
+func TestSleepRestingIsland(t *testing.T) {
+	resetWorld(t)
+	var slept []*Body
+	SetSleepCallbacks(func(b *Body) { slept = append(slept, b) }, nil)
+	_, box := restingBox(t)
+
+	if len(slept) != 1 || slept[0] != box {
+		t.Errorf("sleep callback got %v, expected the box once", slept)
+	}
+	if box.Velocity != VectorZero() || box.AngularVelocity != 0 {
+		t.Errorf("sleeping box moves at %v, %v", box.Velocity, box.AngularVelocity)
+	}
+	pos := box.GetPos()
+	stepFrames(60)
+	if box.GetPos() != pos {
+		t.Errorf("sleeping box moved from %v to %v", pos, box.GetPos())
+	}
+}
+
+func TestSleepWakeOnContact(t *testing.T) {
+	resetWorld(t)
+	_, box := restingBox(t)
+	falling := mustBody(t)(CreateBodyRectangle(NewVector(300, 300), 20, 20, 1, false))
+
+	for i := 0; i < 60 && !box.IsAwake(); i++ {
+		UpdatePhysics(1.0 / 60)
+	}
+	if !box.IsAwake() {
+		t.Errorf("box still asleep with the falling box at %v", falling.GetPos())
+	}
+}
+
+func TestSleepWakeOnApplyForce(t *testing.T) {
+	resetWorld(t)
+	_, box := restingBox(t)
+	box.ApplyForce(NewVector(100, 0))
+	if !box.IsAwake() {
+		t.Fatal("box still asleep after ApplyForce")
+	}
+	UpdatePhysics(1.0 / 60)
+	if box.Velocity.X <= 0 {
+		t.Errorf("velocity %v, expected the force to push the box", box.Velocity)
+	}
+}
+
+func TestSleepWakeOnMoveTo(t *testing.T) {
+	resetWorld(t)
+	_, box := restingBox(t)
+	box.MoveTo(NewVector(300, 200))
+	if !box.IsAwake() {
+		t.Fatal("box still asleep after MoveTo")
+	}
+	UpdatePhysics(1.0 / 60)
+	if box.Velocity.Y <= 0 {
+		t.Errorf("velocity %v, expected the box to fall", box.Velocity)
+	}
+}
+
+func TestSleepDisallowed(t *testing.T) {
+	resetWorld(t)
+	must := mustBody(t)
+	must(CreateBodyRectangle(NewVector(300, 400), 600, 40, 1, true))
+	box := must(CreateBodyRectangle(NewVector(300, 370), 20, 20, 1, false))
+	box.AllowSleep = false
+	stepFrames(120)
+	if !box.IsAwake() {
+		t.Error("box fell asleep with AllowSleep false")
+	}
+}
+
+func TestSleepDisabled(t *testing.T) {
+	resetWorld(t)
+	SetSleepEnabled(false)
+	must := mustBody(t)
+	must(CreateBodyRectangle(NewVector(300, 400), 600, 40, 1, true))
+	box := must(CreateBodyRectangle(NewVector(300, 370), 20, 20, 1, false))
+	stepFrames(120)
+	if !box.IsAwake() {
+		t.Error("box fell asleep with sleeping disabled")
+	}
+}
+
+// a slow kinematic body never sleeps, and putting it to sleep keeps its velocity
+func TestSleepKinematic(t *testing.T) {
+	resetWorld(t)
+	platform := mustBody(t)(CreateKinematicRectangle(NewVector(100, 100), 60, 10, 1))
+	platform.Velocity = NewVector(0.001, 0)
+	start := platform.GetPos()
+	stepFrames(120)
+	if !platform.IsAwake() {
+		t.Fatal("moving kinematic body fell asleep")
+	}
+	if platform.GetPos().X <= start.X {
+		t.Errorf("kinematic body stopped at %v", platform.GetPos())
+	}
+
+	platform.SetAwake(false)
+	if platform.Velocity != NewVector(0.001, 0) {
+		t.Errorf("velocity %v after SetAwake(false), expected it kept", platform.Velocity)
+	}
+
+	platform.Velocity = VectorZero()
+	stepFrames(60)
+	if platform.IsAwake() {
+		t.Error("resting kinematic body still awake")
+	}
+}
@@ -0,0 +1,118 @@
+package phygo
+
+// Snapshot holds the complete simulation state so it can be restored exactly,
+// for rollback netcode or editor undo. Reusing a snapshot with TakeSnapshot
+// avoids allocations once its storage has grown.
+type Snapshot struct {
+	bodies      []*Body
+	bodyStates  []Body
+	joints      []Joint
+	jointStates []Joint
+	manifolds   []Manifold
+	world       worldState
+
+	// quantization used by MarshalBinary
+	posStep, velStep float32
+}
+
+// the global settings and timing state
+type worldState struct {
+	gravity    Vector
+	iterations int
+
+	fixedTimestep      float32
+	maxStepsPerFrame   int
+	accumulator        float32
+	interpolationAlpha float32
+
+	deterministic         bool
+	sleepEnabled          bool
+	linearSleepTolerance  float32
+	angularSleepTolerance float32
+	timeToSleep           float32
+}
+
+func captureWorldState() worldState {
+	return worldState{
+		gravity:               gravity,
+		iterations:            iterations,
+		fixedTimestep:         fixedTimestep,
+		maxStepsPerFrame:      maxStepsPerFrame,
+		accumulator:           accumulator,
+		interpolationAlpha:    interpolationAlpha,
+		deterministic:         deterministic,
+		sleepEnabled:          sleepEnabled,
+		linearSleepTolerance:  linearSleepTolerance,
+		angularSleepTolerance: angularSleepTolerance,
+		timeToSleep:           timeToSleep,
+	}
+}
+
+func (w worldState) restore() {
+	gravity = w.gravity
+	iterations = w.iterations
+	fixedTimestep = w.fixedTimestep
+	maxStepsPerFrame = w.maxStepsPerFrame
+	accumulator = w.accumulator
+	interpolationAlpha = w.interpolationAlpha
+	deterministic = w.deterministic
+	sleepEnabled = w.sleepEnabled
+	linearSleepTolerance = w.linearSleepTolerance
+	angularSleepTolerance = w.angularSleepTolerance
+	timeToSleep = w.timeToSleep
+}
+
+// Captures the current state into s and returns it, a new snapshot is created when s is nil
+func TakeSnapshot(s *Snapshot) *Snapshot {
+	if s == nil {
+		s = &Snapshot{}
+	}
+
+	s.bodies = append(s.bodies[:0], bodies[:bodyCount]...)
+	s.bodyStates = s.bodyStates[:0]
+	for _, b := range bodies[:bodyCount] {
+		s.bodyStates = append(s.bodyStates, *b)
+	}
+
+	s.joints = append(s.joints[:0], joints[:jointCount]...)
+	for i, j := range joints[:jointCount] {
+		if i < len(s.jointStates) {
+			s.jointStates[i] = j.copyInto(s.jointStates[i])
+		} else {
+			s.jointStates = append(s.jointStates, j.copyInto(nil))
+		}
+	}
+	s.jointStates = s.jointStates[:jointCount]
+
+	s.manifolds = append(s.manifolds[:0], manifolds[:manifoldCount]...)
+
+	s.world = captureWorldState()
+
+	return s
+}
+
+// Restores the state captured in s. Bodies and joints keep their identity,
+// so pointers held before the snapshot was taken stay valid.
+func RestoreSnapshot(s *Snapshot) {
+	for i, b := range s.bodies {
+		*b = s.bodyStates[i]
+		bodies[i] = b
+	}
+	for i := len(s.bodies); i < bodyCount; i++ {
+		bodies[i] = nil
+	}
+	bodyCount = len(s.bodies)
+
+	for i, j := range s.joints {
+		s.jointStates[i].copyInto(j)
+		joints[i] = j
+	}
+	for i := len(s.joints); i < jointCount; i++ {
+		joints[i] = nil
+	}
+	jointCount = len(s.joints)
+
+	manifoldCount = copy(manifolds[:], s.manifolds)
+
+	s.world.restore()
+}
@@ -0,0 +1,68 @@
+package phygo
+
+import "time"
+
+// Stats describes the last UpdatePhysics call, timings and counts are summed over its sub-steps
+type Stats struct {
+	Total       time.Duration
+	Integration time.Duration
+	// pair filtering and AABB tests
+	Broadphase time.Duration
+	// SAT tests and contact points
+	Narrowphase time.Duration
+	// contacts and joints
+	Solver time.Duration
+	// island building and sleep timers
+	Islands time.Duration
+
+	Steps       int // simulated sub-steps
+	Bodies      int
+	AwakeBodies int
+	Joints      int
+	// pairs reaching the AABB test
+	CandidatePairs int
+	AABBRejects    int
+	SATTests       int
+	Manifolds      int
+	// contacts ignored because a sub-step found more than the world can hold
+	DroppedContacts int
+}
+
+var (
+	stats         Stats
+	statsCallback func(Stats)
+)
+
+// Returns the statistics of the last UpdatePhysics call
+func GetStats() Stats {
+	return stats
+}
+
+// Sets a function called with the statistics after every UpdatePhysics call, nil removes it
+func SetStatsCallback(callback func(Stats)) {
+	statsCallback = callback
+}
+
+type timer time.Time
+
+func startTimer() timer {
+	return timer(time.Now())
+}
+
+func (t timer) elapsed() time.Duration {
+	return time.Since(time.Time(t))
+}
+
+func finishStats(t timer) {
+	stats.Total = t.elapsed()
+	stats.Bodies = bodyCount
+	stats.Joints = jointCount
+	for _, b := range bodies[:bodyCount] {
+		if b.awake {
+			stats.AwakeBodies++
+		}
+	}
+	if statsCallback != nil {
+		statsCallback(stats)
+	}
+}
@@ -0,0 +1,63 @@
+package phygo
+
+import "testing"
+
+// a box sinking into the ground and two bodies away from everything, stepped
+// once per UpdatePhysics so the counts are those of a single step
+func TestStatsCounts(t *testing.T) {
+	resetWorld(t)
+	SetIteration(1)
+	must := mustBody(t)
+	must(CreateBodyRectangle(NewVector(300, 400), 600, 40, 1, true))
+	must(CreateBodyRectangle(NewVector(100, 371), 20, 20, 1, false))
+	must(CreateBodyRectangle(NewVector(50, 100), 20, 20, 1, true))
+	flying := must(CreateBodyCircle(NewVector(500, 100), 10, 1, false))
+
+	check := func(want Stats) {
+		t.Helper()
+		got := GetStats()
+		if got.Steps != want.Steps || got.Bodies != want.Bodies || got.AwakeBodies != want.AwakeBodies ||
+			got.CandidatePairs != want.CandidatePairs || got.AABBRejects != want.AABBRejects ||
+			got.SATTests != want.SATTests || got.Manifolds != want.Manifolds {
+			t.Errorf("stats %+v, expected %+v", got, want)
+		}
+	}
+
+	// every pair with a dynamic body, only the box and the ground overlap
+	UpdatePhysics(1.0 / 60)
+	check(Stats{Steps: 1, Bodies: 4, AwakeBodies: 2, CandidatePairs: 5, AABBRejects: 4, SATTests: 1, Manifolds: 1})
+
+	// pairs of a sleeping body and a static one are skipped
+	flying.SetAwake(false)
+	UpdatePhysics(1.0 / 60)
+	check(Stats{Steps: 1, Bodies: 4, AwakeBodies: 1, CandidatePairs: 3, AABBRejects: 2, SATTests: 1, Manifolds: 1})
+}
+
+func TestStatsCallback(t *testing.T) {
+	resetWorld(t)
+	setupMixedScene(t)
+	var calls []Stats
+	SetStatsCallback(func(s Stats) { calls = append(calls, s) })
+
+	UpdatePhysics(1.0 / 60)
+	UpdatePhysics(1.0 / 60)
+	// a frame too short for a fixed step still reports its stats
+	SetFixedTimestep(1.0/30, 4)
+	UpdatePhysics(1.0 / 60)
+
+	if len(calls) != 3 {
+		t.Fatalf("callback called %d times for 3 updates", len(calls))
+	}
+	if calls[0].Steps != iterations || calls[2].Steps != 0 {
+		t.Errorf("%d and %d steps, expected %d and 0", calls[0].Steps, calls[2].Steps, iterations)
+	}
+	if calls[2] != GetStats() {
+		t.Errorf("callback got %+v, GetStats returns %+v", calls[2], GetStats())
+	}
+
+	SetStatsCallback(nil)
+	UpdatePhysics(1.0 / 60)
+	if len(calls) != 3 {
+		t.Errorf("callback called after it was removed")
+	}
+}
@@ -0,0 +1,296 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+)
+
+type subpath struct {
+	points []point
+	closed bool
+}
+
+// Parses path data into flattened subpaths, curves staying within the tolerance
+func parsePath(d string, tolerance float64) ([]subpath, error) {
+	s := scanner{s: d}
+	var paths []subpath
+	var pos, start point
+	// the current subpath ended with a close command
+	closed := true
+
+	// control point of the previous curve and its command, for the smooth curves
+	var control point
+	var lastCurve byte
+
+	lineTo := func(p point) {
+		if closed {
+			// drawing after a close continues from the subpath's start
+			paths = append(paths, subpath{points: []point{pos}})
+			closed = false
+		}
+		last := &paths[len(paths)-1]
+		last.points = append(last.points, p)
+		pos = p
+	}
+
+	var cmd byte
+	for !s.done() {
+		if c, ok := s.command(); ok {
+			cmd = c
+		} else if cmd == 0 || cmd == 'z' || cmd == 'Z' {
+			return nil, fmt.Errorf("expected a path command at offset %d", s.i)
+		}
+
+		// relative commands are offset by the current point
+		var base point
+		if cmd >= 'a' {
+			base = pos
+		}
+		upper := cmd &^ 0x20
+		curve := byte(0)
+
+		switch upper {
+		case 'M':
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			pos = p.add(base)
+			start = pos
+			paths = append(paths, subpath{points: []point{pos}})
+			closed = false
+			// the following pairs are lines
+			if cmd == 'M' {
+				cmd = 'L'
+			} else {
+				cmd = 'l'
+			}
+
+		case 'L':
+			p, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			lineTo(p.add(base))
+
+		case 'H':
+			x, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			lineTo(point{x + base.x, pos.y})
+
+		case 'V':
+			y, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			lineTo(point{pos.x, y + base.y})
+
+		case 'C', 'S':
+			var c1 point
+			if upper == 'C' {
+				p, err := s.point()
+				if err != nil {
+					return nil, err
+				}
+				c1 = p.add(base)
+			} else {
+				c1 = reflect(pos, control, lastCurve == 'C')
+			}
+			c2, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			end, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			c2, end = c2.add(base), end.add(base)
+			for _, p := range flattenCubic(pos, c1, c2, end, tolerance) {
+				lineTo(p)
+			}
+			control, curve = c2, 'C'
+
+		case 'Q', 'T':
+			var c point
+			if upper == 'Q' {
+				p, err := s.point()
+				if err != nil {
+					return nil, err
+				}
+				c = p.add(base)
+			} else {
+				c = reflect(pos, control, lastCurve == 'Q')
+			}
+			end, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			end = end.add(base)
+			for _, p := range flattenQuadratic(pos, c, end, tolerance) {
+				lineTo(p)
+			}
+			control, curve = c, 'Q'
+
+		case 'A':
+			var v [3]float64
+			for i := range v {
+				n, err := s.number()
+				if err != nil {
+					return nil, err
+				}
+				v[i] = n
+			}
+			large, err := s.flag()
+			if err != nil {
+				return nil, err
+			}
+			sweep, err := s.flag()
+			if err != nil {
+				return nil, err
+			}
+			end, err := s.point()
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range arcTo(pos, v[0], v[1], v[2], large, sweep, end.add(base), tolerance) {
+				lineTo(p)
+			}
+
+		case 'Z':
+			if !closed {
+				last := &paths[len(paths)-1]
+				if n := len(last.points); n > 1 && last.points[n-1] == last.points[0] {
+					last.points = last.points[:n-1]
+				}
+				last.closed = true
+				closed = true
+			}
+			pos = start
+
+		default:
+			return nil, fmt.Errorf("unknown path command %q", cmd)
+		}
+		lastCurve = curve
+	}
+	return paths, nil
+}
+
+func (s *scanner) point() (point, error) {
+	x, err := s.number()
+	if err != nil {
+		return point{}, err
+	}
+	y, err := s.number()
+	if err != nil {
+		return point{}, err
+	}
+	return point{x, y}, nil
+}
+
+// Returns the control point mirrored around the current point when the
+// previous command was the same kind of curve, the current point otherwise
+func reflect(pos, control point, smooth bool) point {
+	if !smooth {
+		return pos
+	}
+	return pos.scale(2).sub(control)
+}
+
+// Flattens a cubic bezier into the points after p0, the segment count
+// coming from Wang's formula
+func flattenCubic(p0, p1, p2, p3 point, tolerance float64) []point {
+	dd := math.Max(p0.sub(p1.scale(2)).add(p2).len(), p1.sub(p2.scale(2)).add(p3).len())
+	n := max(int(math.Ceil(math.Sqrt(0.75*dd/tolerance))), 1)
+
+	points := make([]point, 0, n)
+	for i := 1; i < n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		p := p0.scale(u * u * u).add(p1.scale(3 * u * u * t)).add(p2.scale(3 * u * t * t)).add(p3.scale(t * t * t))
+		points = append(points, p)
+	}
+	return append(points, p3)
+}
+
+func flattenQuadratic(p0, p1, p2 point, tolerance float64) []point {
+	dd := p0.sub(p1.scale(2)).add(p2).len()
+	n := max(int(math.Ceil(math.Sqrt(0.25*dd/tolerance))), 1)
+
+	points := make([]point, 0, n)
+	for i := 1; i < n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		points = append(points, p0.scale(u*u).add(p1.scale(2*u*t)).add(p2.scale(t*t)))
+	}
+	return append(points, p2)
+}
+
+// Flattens an elliptical arc given in SVG's endpoint form into the points after p0
+func arcTo(p0 point, rx, ry, rotation float64, large, sweep bool, p1 point, tolerance float64) []point {
+	if p0 == p1 {
+		return nil
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		return []point{p1}
+	}
+
+	// conversion to the center form, as described in the SVG specification
+	sin, cos := math.Sincos(rotation * math.Pi / 180)
+	half := p0.sub(p1).scale(0.5)
+	x1 := cos*half.x + sin*half.y
+	y1 := -sin*half.x + cos*half.y
+
+	// radii too small to reach the end point are scaled up
+	if l := x1*x1/(rx*rx) + y1*y1/(ry*ry); l > 1 {
+		rx *= math.Sqrt(l)
+		ry *= math.Sqrt(l)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	coef := math.Sqrt(math.Max(num/den, 0))
+	if large == sweep {
+		coef = -coef
+	}
+	cx1 := coef * rx * y1 / ry
+	cy1 := -coef * ry * x1 / rx
+	mid := p0.add(p1).scale(0.5)
+	center := point{cos*cx1 - sin*cy1 + mid.x, sin*cx1 + cos*cy1 + mid.y}
+
+	u := point{(x1 - cx1) / rx, (y1 - cy1) / ry}
+	v := point{(-x1 - cx1) / rx, (-y1 - cy1) / ry}
+	startAngle := math.Atan2(u.y, u.x)
+	delta := math.Atan2(u.x*v.y-u.y*v.x, u.dot(v))
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	points := flattenArc(center, rx, ry, rotation*math.Pi/180, startAngle, delta, tolerance)
+	points[len(points)-1] = p1
+	return points[1:]
+}
+
+// Returns the points of an ellipse arc from start, sweeping by delta radians,
+// both ends included
+func flattenArc(center point, rx, ry, rotation, start, delta, tolerance float64) []point {
+	r := math.Max(rx, ry)
+	step := math.Pi / 2
+	if tolerance < r {
+		step = math.Min(step, 2*math.Acos(1-tolerance/r))
+	}
+	n := max(int(math.Ceil(math.Abs(delta)/step)), 1)
+
+	sin, cos := math.Sincos(rotation)
+	points := make([]point, 0, n+1)
+	for i := 0; i <= n; i++ {
+		s, c := math.Sincos(start + delta*float64(i)/float64(n))
+		x, y := rx*c, ry*s
+		points = append(points, point{center.x + cos*x - sin*y, center.y + sin*x + cos*y})
+	}
+	return points
+}
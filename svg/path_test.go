@@ -0,0 +1,145 @@
+package svg
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParsePathLines(t *testing.T) {
+	tests := []struct {
+		d    string
+		want []subpath
+	}{
+		{"M10 20 L30 40", []subpath{{points: []point{{10, 20}, {30, 40}}}}},
+		{"m10 20 l20 20 h10 v-5 z", []subpath{{points: []point{{10, 20}, {30, 40}, {40, 40}, {40, 35}}, closed: true}}},
+		{"M0 0 H10 V10 Z", []subpath{{points: []point{{0, 0}, {10, 0}, {10, 10}}, closed: true}}},
+		// pairs after a move are lines
+		{"M0 0 10 0 10 10", []subpath{{points: []point{{0, 0}, {10, 0}, {10, 10}}}}},
+		{"m5 5 10 0", []subpath{{points: []point{{5, 5}, {15, 5}}}}},
+		// a last point on the start is dropped when closing
+		{"M0 0 L10 0 L0 0 Z", []subpath{{points: []point{{0, 0}, {10, 0}}, closed: true}}},
+		// drawing after a close starts a new subpath at the previous start
+		{"M0 0 h10 z l0 10", []subpath{
+			{points: []point{{0, 0}, {10, 0}}, closed: true},
+			{points: []point{{0, 0}, {0, 10}}},
+		}},
+		{"M0 0 L1 1 M5 5 L6 6", []subpath{
+			{points: []point{{0, 0}, {1, 1}}},
+			{points: []point{{5, 5}, {6, 6}}},
+		}},
+		{"M1.5.5-2e1 3", []subpath{{points: []point{{1.5, 0.5}, {-20, 3}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.d, func(t *testing.T) {
+			got, err := parsePath(tt.d, 0.5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			equal := func(a, b subpath) bool {
+				return a.closed == b.closed && slices.Equal(a.points, b.points)
+			}
+			if !slices.EqualFunc(got, tt.want, equal) {
+				t.Errorf("got %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// the flattened points of an arc lie on its ellipse and the arc ends exactly
+// on its end point
+func TestParsePathArcs(t *testing.T) {
+	tests := []struct {
+		name     string
+		d        string
+		end      point
+		center   point
+		rx, ry   float64
+		rotation float64 // degrees
+		// side of the chord the middle of the arc is on, the sign of its y
+		side float64
+	}{
+		{"sweep", "M0 0 A10 10 0 0 1 20 0", point{20, 0}, point{10, 0}, 10, 10, 0, -1},
+		{"relative no sweep", "M0 0 a10 10 0 0 0 20 0", point{20, 0}, point{10, 0}, 10, 10, 0, 1},
+		{"radii scaled up", "M0 0 A1 1 0 0 1 20 0", point{20, 0}, point{10, 0}, 10, 10, 0, -1},
+		{"small arc", "M0 0 A10 10 0 0 1 10 10", point{10, 10}, point{0, 10}, 10, 10, 0, 0},
+		{"large arc", "M0 0 A10 10 0 1 1 10 10", point{10, 10}, point{10, 0}, 10, 10, 0, 0},
+		{"rotated ellipse", "M0 0 A20 10 90 0 1 0 40", point{0, 40}, point{0, 20}, 20, 10, 90, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := parsePath(tt.d, 0.1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != 1 {
+				t.Fatalf("%d subpaths, expected 1", len(paths))
+			}
+			points := paths[0].points
+			sin, cos := math.Sincos(-tt.rotation * math.Pi / 180)
+			for _, p := range points {
+				d := p.sub(tt.center)
+				x, y := cos*d.x-sin*d.y, sin*d.x+cos*d.y
+				if r := x*x/(tt.rx*tt.rx) + y*y/(tt.ry*tt.ry); math.Abs(r-1) > 1e-6 {
+					t.Fatalf("point %v isn't on the ellipse", p)
+				}
+			}
+			if last := points[len(points)-1]; last != tt.end {
+				t.Errorf("ends at %v, expected %v", last, tt.end)
+			}
+			if mid := points[len(points)/2]; tt.side != 0 && math.Signbit(mid.y) != math.Signbit(tt.side) {
+				t.Errorf("middle point %v is on the wrong side", mid)
+			}
+		})
+	}
+}
+
+// flattened curves reach their extremes within the tolerance
+func TestParsePathCurves(t *testing.T) {
+	tests := []struct {
+		d          string
+		end        point
+		minY, maxY float64
+	}{
+		{"M0 0 C0 10 10 10 10 0", point{10, 0}, 0, 7.5},
+		// the smooth curve mirrors the previous control point
+		{"M0 0 C0 10 10 10 10 0 S20 -10 20 0", point{20, 0}, -7.5, 7.5},
+		{"M0 0 Q10 20 20 0", point{20, 0}, 0, 10},
+		{"M0 0 Q10 20 20 0 T40 0", point{40, 0}, -10, 10},
+		{"m0 0 q10 20 20 0 t20 0", point{40, 0}, -10, 10},
+	}
+	const tolerance = 0.1
+	for _, tt := range tests {
+		t.Run(tt.d, func(t *testing.T) {
+			paths, err := parsePath(tt.d, tolerance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			points := paths[0].points
+			if last := points[len(points)-1]; last != tt.end {
+				t.Errorf("ends at %v, expected %v", last, tt.end)
+			}
+			minY, maxY := math.Inf(1), math.Inf(-1)
+			for _, p := range points {
+				minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+			}
+			if math.Abs(minY-tt.minY) > tolerance || math.Abs(maxY-tt.maxY) > tolerance {
+				t.Errorf("y from %v to %v, expected %v to %v", minY, maxY, tt.minY, tt.maxY)
+			}
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, d := range []string{
+		"10 10",
+		"M0 0 Z 5 5",
+		"M0 0 L10",
+		"M0 0 A10 10 0 2 1 5 5",
+		"M0 0 X5 5",
+	} {
+		if paths, err := parsePath(d, 0.5); err == nil {
+			t.Errorf("%q parsed to %v, expected an error", d, paths)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+// Package svg reads collision outlines drawn in SVG editors such as Inkscape
+// and creates phygo bodies from them.
+//
+// <rect>, <circle>, <ellipse>, <line>, <polyline>, <polygon> and <path>
+// elements are read, with the transforms of their groups applied. Curves and
+// arcs are flattened to a tolerance. The viewBox of <svg> elements is scaled
+// to their width and height, so documents sized in mm as Inkscape makes them
+// are read at 96 pixels per inch. Without a viewBox user units are pixels.
+// Rounded rectangle corners, <use> references and CSS are ignored.
+package svg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+type ShapeType int
+
+const (
+	Rectangle ShapeType = iota
+	Circle
+	// closed outline
+	Polygon
+	// open outline
+	Polyline
+)
+
+// Shape is an outline read from the SVG, in pixels
+type Shape struct {
+	Type ShapeType
+	// id attribute of the element
+	Id string
+
+	// center of rectangles and circles
+	Center   phygo.Vector
+	Width    float32
+	Height   float32
+	Rotation float32 // radians
+	Radius   float32
+
+	// outline of every shape, circles are flattened
+	Points []phygo.Vector
+}
+
+type Options struct {
+	// max distance in pixels between curves and their flattened outline, 0.5 when 0
+	Tolerance float32
+
+	// type of the bodies created for rectangles and circles, static by default.
+	// Outlines always become static chains.
+	BodyType phygo.BodyType
+	// density of the bodies created for rectangles and circles, 1 when 0
+	Density float32
+	// thickness in pixels of the rectangles making up outlines, 2 when 0
+	EdgeThickness float32
+}
+
+func (o *Options) defaults() {
+	if o.Tolerance <= 0 {
+		o.Tolerance = 0.5
+	}
+	if o.Density <= 0 {
+		o.Density = 1
+	}
+	if o.EdgeThickness <= 0 {
+		o.EdgeThickness = 2
+	}
+}
+
+// elements whose content is never drawn directly
+var skippedElements = map[string]bool{
+	"defs": true, "clipPath": true, "mask": true, "symbol": true, "pattern": true, "marker": true,
+}
+
+func ParseFile(path string, opts Options) ([]Shape, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, opts)
+}
+
+// Reads the shapes of an SVG document
+func Parse(r io.Reader, opts Options) ([]Shape, error) {
+	opts.defaults()
+
+	var shapes []Shape
+	// transforms of the open elements
+	stack := []affine{identity}
+	skipDepth := 0
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("svg: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 || skippedElements[t.Name.Local] {
+				skipDepth++
+				continue
+			}
+
+			attrs := attributes(t.Attr)
+			m := stack[len(stack)-1]
+			if t.Name.Local == "svg" {
+				local, err := viewport(attrs, len(stack) > 1)
+				if err != nil {
+					return nil, elementError(t.Name.Local, attrs, err)
+				}
+				m = m.mul(local)
+			}
+			if value, ok := attrs["transform"]; ok {
+				local, err := parseTransform(value)
+				if err != nil {
+					return nil, elementError(t.Name.Local, attrs, err)
+				}
+				m = m.mul(local)
+			}
+			stack = append(stack, m)
+
+			found, err := readElement(t.Name.Local, attrs, m, opts.Tolerance)
+			if err != nil {
+				return nil, elementError(t.Name.Local, attrs, err)
+			}
+			shapes = append(shapes, found...)
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return shapes, nil
+}
+
+func elementError(name string, attrs map[string]string, err error) error {
+	if id := attrs["id"]; id != "" {
+		return fmt.Errorf("svg: <%s id=%q>: %v", name, id, err)
+	}
+	return fmt.Errorf("svg: <%s>: %v", name, err)
+}
+
+func attributes(list []xml.Attr) map[string]string {
+	attrs := make(map[string]string, len(list))
+	for _, a := range list {
+		attrs[a.Name.Local] = a.Value
+	}
+	return attrs
+}
+
+// pixels per absolute length unit
+var unitScales = map[string]float64{
+	"": 1, "px": 1, "in": 96, "cm": 96 / 2.54, "mm": 96 / 25.4, "pt": 96.0 / 72, "pc": 16,
+}
+
+// Parses a length with an optional absolute unit into pixels
+func parseLength(text string) (float64, bool) {
+	s := scanner{s: strings.TrimSpace(text)}
+	v, err := s.number()
+	if err != nil {
+		return 0, false
+	}
+	scale, ok := unitScales[s.s[s.i:]]
+	return v * scale, ok
+}
+
+// Reads the listed attributes as lengths, missing ones are 0
+func lengths(attrs map[string]string, names ...string) ([]float64, error) {
+	values := make([]float64, len(names))
+	for i, name := range names {
+		text, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		v, ok := parseLength(text)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s %q", name, text)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Returns the transform from the user units of an <svg> element to the ones
+// of its parent, fitting the viewBox into the width and height as
+// preserveAspectRatio asks. A missing or relative width or height comes from
+// the viewBox, keeping its aspect ratio. Only nested elements are moved by x and y.
+func viewport(attrs map[string]string, nested bool) (affine, error) {
+	m := identity
+	if nested {
+		v, err := lengths(attrs, "x", "y")
+		if err != nil {
+			return identity, err
+		}
+		m.e, m.f = v[0], v[1]
+	}
+
+	text, ok := attrs["viewBox"]
+	if !ok {
+		return m, nil
+	}
+	s := scanner{s: text}
+	var box [4]float64
+	for i := range box {
+		v, err := s.number()
+		if err != nil {
+			return identity, fmt.Errorf("invalid viewBox %q", text)
+		}
+		box[i] = v
+	}
+	if !s.done() || box[2] <= 0 || box[3] <= 0 {
+		return identity, fmt.Errorf("invalid viewBox %q", text)
+	}
+
+	width, hasWidth := parseLength(attrs["width"])
+	height, hasHeight := parseLength(attrs["height"])
+	switch {
+	case !hasWidth && !hasHeight:
+		width, height = box[2], box[3]
+	case !hasWidth:
+		width = height * box[2] / box[3]
+	case !hasHeight:
+		height = width * box[3] / box[2]
+	}
+
+	sx, sy := width/box[2], height/box[3]
+	align, mode, _ := strings.Cut(strings.TrimSpace(attrs["preserveAspectRatio"]), " ")
+	if align == "" {
+		align = "xMidYMid"
+	}
+	var alignX, alignY float64
+	if align != "none" {
+		if len(align) != 8 || align[0] != 'x' || align[4] != 'Y' {
+			return identity, fmt.Errorf("invalid preserveAspectRatio %q", attrs["preserveAspectRatio"])
+		}
+		if strings.TrimSpace(mode) == "slice" {
+			sx = math.Max(sx, sy)
+		} else {
+			sx = math.Min(sx, sy)
+		}
+		sy = sx
+		// the share of the free space left before the viewBox
+		shares := map[string]float64{"Min": 0, "Mid": 0.5, "Max": 1}
+		var okX, okY bool
+		alignX, okX = shares[align[1:4]]
+		alignY, okY = shares[align[5:8]]
+		if !okX || !okY {
+			return identity, fmt.Errorf("invalid preserveAspectRatio %q", attrs["preserveAspectRatio"])
+		}
+	}
+
+	m.e += (width-box[2]*sx)*alignX - box[0]*sx
+	m.f += (height-box[3]*sy)*alignY - box[1]*sy
+	m.a, m.d = sx, sy
+	return m, nil
+}
+
+func readElement(name string, attrs map[string]string, m affine, tolerance float32) ([]Shape, error) {
+	id := attrs["id"]
+	// flattening happens before the transform so the tolerance is scaled down
+	localTolerance := float64(tolerance) / m.maxScale()
+
+	switch name {
+	case "rect":
+		v, err := lengths(attrs, "x", "y", "width", "height")
+		if err != nil {
+			return nil, err
+		}
+		x, y, w, h := v[0], v[1], v[2], v[3]
+		if w <= 0 || h <= 0 {
+			return nil, nil
+		}
+		return []Shape{rectangle(id, m, x, y, w, h)}, nil
+
+	case "circle", "ellipse":
+		var v []float64
+		var err error
+		if name == "circle" {
+			v, err = lengths(attrs, "cx", "cy", "r", "r")
+		} else {
+			v, err = lengths(attrs, "cx", "cy", "rx", "ry")
+		}
+		if err != nil {
+			return nil, err
+		}
+		cx, cy, rx, ry := v[0], v[1], v[2], v[3]
+		if rx <= 0 || ry <= 0 {
+			return nil, nil
+		}
+		return []Shape{ellipse(id, m, cx, cy, rx, ry, localTolerance)}, nil
+
+	case "line":
+		v, err := lengths(attrs, "x1", "y1", "x2", "y2")
+		if err != nil {
+			return nil, err
+		}
+		points := []point{{v[0], v[1]}, {v[2], v[3]}}
+		return []Shape{outline(id, m, points, false)}, nil
+
+	case "polyline", "polygon":
+		s := scanner{s: attrs["points"]}
+		var points []point
+		for !s.done() {
+			x, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			y, err := s.number()
+			if err != nil {
+				return nil, err
+			}
+			points = append(points, point{x, y})
+		}
+		if len(points) < 2 {
+			return nil, nil
+		}
+		return []Shape{outline(id, m, points, name == "polygon")}, nil
+
+	case "path":
+		subpaths, err := parsePath(attrs["d"], localTolerance)
+		if err != nil {
+			return nil, err
+		}
+		var shapes []Shape
+		for _, sp := range subpaths {
+			if len(sp.points) >= 2 {
+				shapes = append(shapes, outline(id, m, sp.points, sp.closed))
+			}
+		}
+		return shapes, nil
+	}
+	return nil, nil
+}
+
+func toVectors(m affine, points []point) []phygo.Vector {
+	vectors := make([]phygo.Vector, len(points))
+	for i, p := range points {
+		p = m.apply(p)
+		vectors[i] = phygo.NewVector(float32(p.x), float32(p.y))
+	}
+	return vectors
+}
+
+func outline(id string, m affine, points []point, closed bool) Shape {
+	shape := Shape{Type: Polyline, Id: id, Points: toVectors(m, points)}
+	if closed {
+		shape.Type = Polygon
+	}
+	return shape
+}
+
+// Stays a rectangle unless the transform skews it
+func rectangle(id string, m affine, x, y, w, h float64) Shape {
+	corners := []point{{x, y}, {x + w, y}, {x + w, y + h}, {x, y + h}}
+	shape := outline(id, m, corners, true)
+
+	p0, p1, p3 := m.apply(corners[0]), m.apply(corners[1]), m.apply(corners[3])
+	e1, e2 := p1.sub(p0), p3.sub(p0)
+	if math.Abs(e1.dot(e2)) > 1e-6*e1.len()*e2.len() {
+		return shape
+	}
+	center := m.apply(point{x + w/2, y + h/2})
+	shape.Type = Rectangle
+	shape.Center = phygo.NewVector(float32(center.x), float32(center.y))
+	shape.Width = float32(e1.len())
+	shape.Height = float32(e2.len())
+	shape.Rotation = float32(math.Atan2(e1.y, e1.x))
+	return shape
+}
+
+// Stays a circle when both radii are equal after the transform
+func ellipse(id string, m affine, cx, cy, rx, ry, tolerance float64) Shape {
+	points := flattenArc(point{cx, cy}, rx, ry, 0, 0, 2*math.Pi, tolerance)
+	shape := outline(id, m, points[:len(points)-1], true)
+
+	ex := m.applyVector(point{rx, 0})
+	ey := m.applyVector(point{0, ry})
+	if math.Abs(ex.len()-ey.len()) > 1e-6*ex.len() || math.Abs(ex.dot(ey)) > 1e-6*ex.len()*ey.len() {
+		return shape
+	}
+	center := m.apply(point{cx, cy})
+	shape.Type = Circle
+	shape.Center = phygo.NewVector(float32(center.x), float32(center.y))
+	shape.Radius = float32(ex.len())
+	return shape
+}
+
+// Creates the bodies of the shapes: rectangles and circles become bodies of
+// the given type, outlines static chains. No body is left in the world on error.
+func CreateBodies(shapes []Shape, opts Options) ([]*phygo.Body, error) {
+	opts.defaults()
+
+	var bodies []*phygo.Body
+	for _, s := range shapes {
+		var b *phygo.Body
+		var chain []*phygo.Body
+		var err error
+		switch s.Type {
+		case Rectangle:
+			if b, err = phygo.CreateBodyRectangle(s.Center, s.Width, s.Height, opts.Density, true); err == nil {
+				b.RotateTo(s.Rotation)
+			}
+		case Circle:
+			b, err = phygo.CreateBodyCircle(s.Center, s.Radius, opts.Density, true)
+		default:
+			chain, err = phygo.CreateChain(s.Points, opts.EdgeThickness, s.Type == Polygon)
+		}
+		if err != nil {
+			for _, created := range bodies {
+				phygo.RemoveBody(created)
+			}
+			if s.Id != "" {
+				return nil, fmt.Errorf("svg: shape %q: %w", s.Id, err)
+			}
+			return nil, fmt.Errorf("svg: %w", err)
+		}
+
+		if b != nil {
+			b.SetBodyType(opts.BodyType)
+			bodies = append(bodies, b)
+		}
+		bodies = append(bodies, chain...)
+	}
+	return bodies, nil
+}
+
+// Reads the shapes of an SVG document and creates their bodies
+func Load(r io.Reader, opts Options) ([]*phygo.Body, error) {
+	shapes, err := Parse(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return CreateBodies(shapes, opts)
+}
+
+func LoadFile(path string, opts Options) ([]*phygo.Body, error) {
+	shapes, err := ParseFile(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return CreateBodies(shapes, opts)
+}
@@ -0,0 +1,87 @@
+package svg
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+const mm = 96 / 25.4
+
+func TestViewBox(t *testing.T) {
+	tests := []struct {
+		name   string
+		root   string
+		center phygo.Vector
+		width  float64
+		height float64
+	}{
+		{"no viewBox", `width="210mm" height="297mm"`, phygo.NewVector(25, 40), 30, 40},
+		{"mm document", `width="210mm" height="297mm" viewBox="0 0 210 297"`, phygo.NewVector(25*mm, 40*mm), 30 * mm, 40 * mm},
+		{"width only", `width="420" viewBox="0 0 210 297"`, phygo.NewVector(50, 80), 60, 80},
+		{"viewBox only", `viewBox="0 0 100 100"`, phygo.NewVector(25, 40), 30, 40},
+		{"offset", `width="200" height="200" viewBox="-50 -50 100 100"`, phygo.NewVector(150, 180), 60, 80},
+		// meet fits the viewBox and centers it
+		{"meet", `width="200" height="100" viewBox="0 0 100 100"`, phygo.NewVector(75, 40), 30, 40},
+		{"xMinYMin meet", `width="200" height="100" viewBox="0 0 100 100" preserveAspectRatio="xMinYMin"`, phygo.NewVector(25, 40), 30, 40},
+		{"xMaxYMax slice", `width="200" height="100" viewBox="0 0 100 100" preserveAspectRatio="xMaxYMax slice"`, phygo.NewVector(50, -20), 60, 80},
+		{"none", `width="200" height="100" viewBox="0 0 100 100" preserveAspectRatio="none"`, phygo.NewVector(50, 40), 60, 40},
+		{"inches", `width="1in" height="1in" viewBox="0 0 96 96"`, phygo.NewVector(25, 40), 30, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `<svg ` + tt.root + `><rect x="10" y="20" width="30" height="40"/></svg>`
+			shapes, err := Parse(strings.NewReader(doc), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := shapes[0]
+			if s.Type != Rectangle ||
+				math.Abs(float64(s.Center.X-tt.center.X)) > 1e-3 || math.Abs(float64(s.Center.Y-tt.center.Y)) > 1e-3 ||
+				math.Abs(float64(s.Width)-tt.width) > 1e-3 || math.Abs(float64(s.Height)-tt.height) > 1e-3 {
+				t.Errorf("got a %vx%v rectangle at %v, expected %vx%v at %v", s.Width, s.Height, s.Center, tt.width, tt.height, tt.center)
+			}
+		})
+	}
+}
+
+// nested <svg> elements are moved by x and y, groups apply their transforms
+func TestNestedViewports(t *testing.T) {
+	doc := `<svg viewBox="0 0 100 100">
+		<g transform="translate(10 0)">
+			<svg x="5" y="5" width="20" height="20" viewBox="0 0 10 10">
+				<g transform="scale(2)"><circle cx="1" cy="1" r="1"/></g>
+			</svg>
+		</g>
+		<rect x="1mm" y="0" width="2mm" height="1mm"/>
+	</svg>`
+	shapes, err := Parse(strings.NewReader(doc), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shapes) != 2 {
+		t.Fatalf("%d shapes, expected 2", len(shapes))
+	}
+	if c := shapes[0]; c.Type != Circle || c.Center != phygo.NewVector(19, 9) || c.Radius != 4 {
+		t.Errorf("circle of radius %v at %v, expected radius 4 at (19, 9)", c.Radius, c.Center)
+	}
+	if r := shapes[1]; math.Abs(float64(r.Width)-2*mm) > 1e-3 || math.Abs(float64(r.Center.X)-2*mm) > 1e-3 {
+		t.Errorf("rectangle %v wide at %v, expected lengths in mm", r.Width, r.Center)
+	}
+}
+
+func TestViewBoxErrors(t *testing.T) {
+	for _, root := range []string{
+		`viewBox="0 0 100"`,
+		`viewBox="0 0 0 100"`,
+		`viewBox="0 0 100 100" preserveAspectRatio="middle"`,
+		`viewBox="0 0 100 100" preserveAspectRatio="xMidYTop"`,
+	} {
+		doc := `<svg ` + root + `><rect width="1" height="1"/></svg>`
+		if _, err := Parse(strings.NewReader(doc), Options{}); err == nil {
+			t.Errorf("%s parsed, expected an error", root)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package svg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type point struct {
+	x, y float64
+}
+
+func (p point) add(o point) point {
+	return point{p.x + o.x, p.y + o.y}
+}
+
+func (p point) sub(o point) point {
+	return point{p.x - o.x, p.y - o.y}
+}
+
+func (p point) scale(s float64) point {
+	return point{p.x * s, p.y * s}
+}
+
+func (p point) dot(o point) float64 {
+	return p.x*o.x + p.y*o.y
+}
+
+func (p point) len() float64 {
+	return math.Hypot(p.x, p.y)
+}
+
+// 2D affine transform as in SVG's matrix(a b c d e f)
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+var identity = affine{a: 1, d: 1}
+
+// Returns the transform applying o first, then m
+func (m affine) mul(o affine) affine {
+	return affine{
+		a: m.a*o.a + m.c*o.b,
+		b: m.b*o.a + m.d*o.b,
+		c: m.a*o.c + m.c*o.d,
+		d: m.b*o.c + m.d*o.d,
+		e: m.a*o.e + m.c*o.f + m.e,
+		f: m.b*o.e + m.d*o.f + m.f,
+	}
+}
+
+func (m affine) apply(p point) point {
+	return point{m.a*p.x + m.c*p.y + m.e, m.b*p.x + m.d*p.y + m.f}
+}
+
+// applies the transform without the translation
+func (m affine) applyVector(p point) point {
+	return point{m.a*p.x + m.c*p.y, m.b*p.x + m.d*p.y}
+}
+
+// Returns the largest factor lengths are scaled by
+func (m affine) maxScale() float64 {
+	// largest singular value of the linear part
+	p := (m.a*m.a + m.b*m.b + m.c*m.c + m.d*m.d) / 2
+	q := m.a*m.d - m.b*m.c
+	s := math.Sqrt(p + math.Sqrt(math.Max(p*p-q*q, 0)))
+	if s == 0 {
+		return 1
+	}
+	return s
+}
+
+// Parses a transform attribute, a list of transform functions applied right to left
+func parseTransform(text string) (affine, error) {
+	m := identity
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		open := strings.IndexByte(rest, '(')
+		end := strings.IndexByte(rest, ')')
+		if open == -1 || end < open {
+			return identity, fmt.Errorf("invalid transform %q", text)
+		}
+		name := strings.TrimSpace(rest[:open])
+
+		s := scanner{s: rest[open+1 : end]}
+		var args []float64
+		for !s.done() {
+			v, err := s.number()
+			if err != nil {
+				return identity, fmt.Errorf("invalid transform %q", text)
+			}
+			args = append(args, v)
+		}
+
+		t, err := transformFunction(name, args)
+		if err != nil {
+			return identity, err
+		}
+		m = m.mul(t)
+		rest = strings.TrimLeft(rest[end+1:], " \t\r\n,")
+	}
+	return m, nil
+}
+
+func transformFunction(name string, args []float64) (affine, error) {
+	count := func(allowed ...int) error {
+		for _, n := range allowed {
+			if len(args) == n {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s takes %v arguments, got %d", name, allowed, len(args))
+	}
+
+	switch name {
+	case "matrix":
+		if err := count(6); err != nil {
+			return identity, err
+		}
+		return affine{args[0], args[1], args[2], args[3], args[4], args[5]}, nil
+	case "translate":
+		if err := count(1, 2); err != nil {
+			return identity, err
+		}
+		t := identity
+		t.e = args[0]
+		if len(args) == 2 {
+			t.f = args[1]
+		}
+		return t, nil
+	case "scale":
+		if err := count(1, 2); err != nil {
+			return identity, err
+		}
+		sx, sy := args[0], args[0]
+		if len(args) == 2 {
+			sy = args[1]
+		}
+		return affine{a: sx, d: sy}, nil
+	case "rotate":
+		if err := count(1, 3); err != nil {
+			return identity, err
+		}
+		sin, cos := math.Sincos(args[0] * math.Pi / 180)
+		r := affine{a: cos, b: sin, c: -sin, d: cos}
+		if len(args) == 3 {
+			// rotating around (cx, cy)
+			to := affine{a: 1, d: 1, e: args[1], f: args[2]}
+			back := affine{a: 1, d: 1, e: -args[1], f: -args[2]}
+			r = to.mul(r).mul(back)
+		}
+		return r, nil
+	case "skewX":
+		if err := count(1); err != nil {
+			return identity, err
+		}
+		return affine{a: 1, c: math.Tan(args[0] * math.Pi / 180), d: 1}, nil
+	case "skewY":
+		if err := count(1); err != nil {
+			return identity, err
+		}
+		return affine{a: 1, b: math.Tan(args[0] * math.Pi / 180), d: 1}, nil
+	}
+	return identity, fmt.Errorf("unknown transform %q", name)
+}
+
+var errExpectedNumber = errors.New("expected a number")
+
+// scanner reads the numbers, flags and commands of attribute values
+type scanner struct {
+	s string
+	i int
+}
+
+func (s *scanner) skipSeparators() {
+	for s.i < len(s.s) {
+		switch s.s[s.i] {
+		case ' ', '\t', '\r', '\n', ',':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+func (s *scanner) done() bool {
+	s.skipSeparators()
+	return s.i >= len(s.s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Reads a number, which can directly follow the previous one as in "1.5.5" or "1-2"
+func (s *scanner) number() (float64, error) {
+	s.skipSeparators()
+	start := s.i
+	if s.i < len(s.s) && (s.s[s.i] == '+' || s.s[s.i] == '-') {
+		s.i++
+	}
+	digits := 0
+	for s.i < len(s.s) && isDigit(s.s[s.i]) {
+		s.i++
+		digits++
+	}
+	if s.i < len(s.s) && s.s[s.i] == '.' {
+		s.i++
+		for s.i < len(s.s) && isDigit(s.s[s.i]) {
+			s.i++
+			digits++
+		}
+	}
+	if digits == 0 {
+		s.i = start
+		return 0, errExpectedNumber
+	}
+	if s.i < len(s.s) && (s.s[s.i] == 'e' || s.s[s.i] == 'E') {
+		exp := s.i + 1
+		if exp < len(s.s) && (s.s[exp] == '+' || s.s[exp] == '-') {
+			exp++
+		}
+		if exp < len(s.s) && isDigit(s.s[exp]) {
+			for s.i = exp; s.i < len(s.s) && isDigit(s.s[s.i]); s.i++ {
+			}
+		}
+	}
+	return strconv.ParseFloat(s.s[start:s.i], 64)
+}
+
+// Reads an arc flag, which can be written without separators as in "a1 1 0 011 1"
+func (s *scanner) flag() (bool, error) {
+	s.skipSeparators()
+	if s.i < len(s.s) && (s.s[s.i] == '0' || s.s[s.i] == '1') {
+		s.i++
+		return s.s[s.i-1] == '1', nil
+	}
+	return false, fmt.Errorf("expected an arc flag")
+}
+
+// Returns the next path command letter, if the next token is one
+func (s *scanner) command() (byte, bool) {
+	s.skipSeparators()
+	if s.i < len(s.s) {
+		c := s.s[s.i]
+		if (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') && c != 'e' && c != 'E' {
+			s.i++
+			return c, true
+		}
+	}
+	return 0, false
+}
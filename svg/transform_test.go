@@ -0,0 +1,61 @@
+package svg
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b point) bool {
+	return math.Abs(a.x-b.x) < 1e-9 && math.Abs(a.y-b.y) < 1e-9
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		text    string
+		in, out point
+	}{
+		{"translate(10 20)", point{1, 1}, point{11, 21}},
+		{"translate(10)", point{1, 1}, point{11, 1}},
+		{"scale(2)", point{1, 2}, point{2, 4}},
+		{"scale(2, 3)", point{1, 1}, point{2, 3}},
+		{"rotate(90)", point{1, 0}, point{0, 1}},
+		{"rotate(90 10 10)", point{20, 10}, point{10, 20}},
+		{"skewX(45)", point{0, 1}, point{1, 1}},
+		{"skewY(45)", point{1, 0}, point{1, 1}},
+		{"matrix(1 2 3 4 5 6)", point{1, 1}, point{9, 12}},
+		// the list applies right to left
+		{"translate(10 20) scale(2)", point{1, 1}, point{12, 22}},
+		{"scale(2) translate(10 20)", point{1, 1}, point{22, 42}},
+		{"translate(10),rotate(180)", point{1, 0}, point{9, 0}},
+		{"rotate(90) translate(5 0) scale(2 1)", point{1, 1}, point{-1, 7}},
+		{"  ", point{3, 4}, point{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			m, err := parseTransform(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.apply(tt.in); !near(got, tt.out) {
+				t.Errorf("%v moved to %v, expected %v", tt.in, got, tt.out)
+			}
+		})
+	}
+}
+
+func TestParseTransformErrors(t *testing.T) {
+	for _, text := range []string{"rotate(1 2)", "spin(1)", "translate(1", "scale()", "matrix(1 2 3)", "translate(a)"} {
+		if _, err := parseTransform(text); err == nil {
+			t.Errorf("%q parsed, expected an error", text)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	a, _ := parseTransform("translate(3 4)")
+	b, _ := parseTransform("rotate(30) scale(2 3)")
+	p := point{5, -2}
+	if got, want := a.mul(b).apply(p), a.apply(b.apply(p)); !near(got, want) {
+		t.Errorf("the product moved %v to %v, expected %v", p, got, want)
+	}
+}
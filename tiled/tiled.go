@@ -0,0 +1,538 @@
+// Package tiled creates phygo bodies from maps made with the Tiled editor
+// and saved in its JSON format (.tmj / .json).
+//
+// Objects of object layers become bodies: rectangles and tile objects become
+// rectangles, ellipses circles, and polygons and polylines chains of thin
+// rectangles along their edges. Tile layers with a true "solid" property, and
+// tiles with one in an embedded tileset, become rectangles, merging adjacent
+// solid tiles to keep the body count low.
+//
+// The "friction", "staticFriction", "dynamicFriction", "restitution" and
+// "bodyType" ("static" or "kinematic") custom properties of the map, layers
+// and objects are applied to the bodies, the innermost one winning.
+//
+// Collision filters can't be mapped: phygo has no collision filtering, every
+// body collides with every other one, so filter properties are ignored like
+// any other unknown property.
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+// flip flags stored in the high bits of tile gids
+const gidMask = 0x0fffffff
+
+type Options struct {
+	// type of the created bodies when not set by a property, static by default
+	BodyType phygo.BodyType
+	// thickness in pixels of the rectangles making up polygon and polyline edges, 2 when 0
+	EdgeThickness float32
+	// names of the layers to import, all when empty
+	Layers []string
+}
+
+// Object is an imported map object with the bodies created for it
+type Object struct {
+	Id     int
+	Name   string
+	Class  string
+	Bodies []*phygo.Body
+}
+
+type Map struct {
+	Width      int // in tiles
+	Height     int
+	TileWidth  int
+	TileHeight int
+	// every created body
+	Bodies  []*phygo.Body
+	Objects []Object
+}
+
+type mapFile struct {
+	Orientation string     `json:"orientation"`
+	Width       int        `json:"width"`
+	Height      int        `json:"height"`
+	TileWidth   int        `json:"tilewidth"`
+	TileHeight  int        `json:"tileheight"`
+	Layers      []layer    `json:"layers"`
+	Tilesets    []tileset  `json:"tilesets"`
+	Properties  []property `json:"properties"`
+}
+
+type layer struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	OffsetX     float32         `json:"offsetx"`
+	OffsetY     float32         `json:"offsety"`
+	Width       int             `json:"width"`
+	Height      int             `json:"height"`
+	Data        json.RawMessage `json:"data"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Chunks      []chunk         `json:"chunks"`
+	Objects     []object        `json:"objects"`
+	Layers      []layer         `json:"layers"`
+	Properties  []property      `json:"properties"`
+}
+
+type chunk struct {
+	X      int             `json:"x"`
+	Y      int             `json:"y"`
+	Width  int             `json:"width"`
+	Height int             `json:"height"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type object struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Class      string     `json:"class"`
+	X          float32    `json:"x"`
+	Y          float32    `json:"y"`
+	Width      float32    `json:"width"`
+	Height     float32    `json:"height"`
+	Rotation   float32    `json:"rotation"` // degrees clockwise
+	Gid        uint32     `json:"gid"`
+	Ellipse    bool       `json:"ellipse"`
+	Point      bool       `json:"point"`
+	Polygon    []point    `json:"polygon"`
+	Polyline   []point    `json:"polyline"`
+	Properties []property `json:"properties"`
+}
+
+type point struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type property struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+type tileset struct {
+	FirstGid int    `json:"firstgid"`
+	Tiles    []tile `json:"tiles"`
+}
+
+type tile struct {
+	Id         int        `json:"id"`
+	Properties []property `json:"properties"`
+}
+
+// properties inherited from the map and the parent layers
+type properties map[string]json.RawMessage
+
+func (p properties) with(list []property) properties {
+	if len(list) == 0 {
+		return p
+	}
+	merged := make(properties, len(p)+len(list))
+	for name, value := range p {
+		merged[name] = value
+	}
+	for _, prop := range list {
+		merged[prop.Name] = prop.Value
+	}
+	return merged
+}
+
+func (p properties) bool(name string) (bool, error) {
+	var v bool
+	if raw, ok := p[name]; ok {
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return false, fmt.Errorf("property %q must be a bool", name)
+		}
+	}
+	return v, nil
+}
+
+func (p properties) float(name string) (float32, bool, error) {
+	raw, ok := p[name]
+	if !ok {
+		return 0, false, nil
+	}
+	var v float32
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, false, fmt.Errorf("property %q must be a number", name)
+	}
+	return v, true, nil
+}
+
+type loader struct {
+	opts Options
+	m    *Map
+	// gids of the tiles marked solid in embedded tilesets
+	solidTiles map[uint32]bool
+}
+
+func LoadFile(path string, opts Options) (*Map, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, opts)
+}
+
+// Reads a Tiled JSON map and creates its bodies in the phygo world
+func Load(r io.Reader, opts Options) (*Map, error) {
+	var file mapFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("tiled: %w", err)
+	}
+	if file.Orientation != "" && file.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("tiled: %s maps are not supported", file.Orientation)
+	}
+	if opts.EdgeThickness <= 0 {
+		opts.EdgeThickness = 2
+	}
+
+	l := loader{
+		opts: opts,
+		m: &Map{
+			Width:      file.Width,
+			Height:     file.Height,
+			TileWidth:  file.TileWidth,
+			TileHeight: file.TileHeight,
+		},
+		solidTiles: map[uint32]bool{},
+	}
+	for _, ts := range file.Tilesets {
+		for _, t := range ts.Tiles {
+			solid, err := properties(nil).with(t.Properties).bool("solid")
+			if err != nil {
+				return nil, fmt.Errorf("tiled: tile %d: %v", ts.FirstGid+t.Id, err)
+			}
+			if solid {
+				l.solidTiles[uint32(ts.FirstGid+t.Id)] = true
+			}
+		}
+	}
+
+	props := properties(nil).with(file.Properties)
+	for _, ly := range file.Layers {
+		if err := l.layer(ly, props, 0, 0); err != nil {
+			// not leaving a partly loaded map in the world
+			for _, b := range l.m.Bodies {
+				phygo.RemoveBody(b)
+			}
+			return nil, err
+		}
+	}
+	return l.m, nil
+}
+
+func (l *loader) wanted(name string) bool {
+	if len(l.opts.Layers) == 0 {
+		return true
+	}
+	for _, n := range l.opts.Layers {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *loader) layer(ly layer, props properties, offsetX, offsetY float32) error {
+	props = props.with(ly.Properties)
+	offsetX += ly.OffsetX
+	offsetY += ly.OffsetY
+
+	switch ly.Type {
+	case "group":
+		for _, child := range ly.Layers {
+			if err := l.layer(child, props, offsetX, offsetY); err != nil {
+				return err
+			}
+		}
+	case "objectgroup":
+		if !l.wanted(ly.Name) {
+			return nil
+		}
+		for _, o := range ly.Objects {
+			if err := l.object(o, props, offsetX, offsetY); err != nil {
+				return fmt.Errorf("tiled: layer %q: object %d: %v", ly.Name, o.Id, err)
+			}
+		}
+	case "tilelayer":
+		if !l.wanted(ly.Name) {
+			return nil
+		}
+		if err := l.tileLayer(ly, props, offsetX, offsetY); err != nil {
+			return fmt.Errorf("tiled: layer %q: %v", ly.Name, err)
+		}
+	}
+	return nil
+}
+
+func (l *loader) tileLayer(ly layer, props properties, offsetX, offsetY float32) error {
+	solid, err := props.bool("solid")
+	if err != nil {
+		return err
+	}
+	if !solid && len(l.solidTiles) == 0 {
+		return nil
+	}
+
+	if len(ly.Chunks) == 0 {
+		ly.Chunks = []chunk{{Width: ly.Width, Height: ly.Height, Data: ly.Data}}
+	}
+	for _, c := range ly.Chunks {
+		gids, err := decodeData(c.Data, ly.Encoding, ly.Compression)
+		if err != nil {
+			return err
+		}
+		if len(gids) != c.Width*c.Height {
+			return fmt.Errorf("has %d tiles, expected %d", len(gids), c.Width*c.Height)
+		}
+
+		grid := make([]bool, len(gids))
+		for i, gid := range gids {
+			gid &= gidMask
+			grid[i] = gid != 0 && (solid || l.solidTiles[gid])
+		}
+
+		tw, th := float32(l.m.TileWidth), float32(l.m.TileHeight)
+		err = mergeTiles(grid, c.Width, c.Height, func(x, y, w, h int) error {
+			pos := phygo.NewVector(
+				offsetX+(float32(c.X+x)+float32(w)/2)*tw,
+				offsetY+(float32(c.Y+y)+float32(h)/2)*th,
+			)
+			b, err := phygo.CreateBodyRectangle(pos, float32(w)*tw, float32(h)*th, 1, true)
+			if err != nil {
+				return err
+			}
+			if err := l.apply(b, props); err != nil {
+				phygo.RemoveBody(b)
+				return err
+			}
+			l.m.Bodies = append(l.m.Bodies, b)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Covers the solid cells of the grid with as few rectangles as the greedy
+// approach finds: each run of a row is extended down while the rows below are solid
+func mergeTiles(grid []bool, width, height int, emit func(x, y, w, h int) error) error {
+	used := make([]bool, len(grid))
+	free := func(x, y int) bool {
+		i := y*width + x
+		return grid[i] && !used[i]
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !free(x, y) {
+				continue
+			}
+			w := 1
+			for x+w < width && free(x+w, y) {
+				w++
+			}
+			h := 1
+		grow:
+			for y+h < height {
+				for i := x; i < x+w; i++ {
+					if !free(i, y+h) {
+						break grow
+					}
+				}
+				h++
+			}
+
+			for j := y; j < y+h; j++ {
+				for i := x; i < x+w; i++ {
+					used[j*width+i] = true
+				}
+			}
+			if err := emit(x, y, w, h); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeData(data json.RawMessage, encoding, compression string) ([]uint32, error) {
+	if encoding == "" || encoding == "csv" {
+		var gids []uint32
+		if err := json.Unmarshal(data, &gids); err != nil {
+			return nil, fmt.Errorf("invalid tile data: %v", err)
+		}
+		return gids, nil
+	}
+	if encoding != "base64" {
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return nil, fmt.Errorf("invalid tile data: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tile data: %v", err)
+	}
+
+	var r io.Reader
+	switch compression {
+	case "":
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid tile data: %v", err)
+	}
+	if r != nil {
+		if raw, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("invalid tile data: %v", err)
+		}
+	}
+
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("invalid tile data length %d", len(raw))
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return gids, nil
+}
+
+func (l *loader) object(o object, props properties, offsetX, offsetY float32) error {
+	props = props.with(o.Properties)
+	class := o.Class
+	if class == "" {
+		class = o.Type
+	}
+	obj := Object{Id: o.Id, Name: o.Name, Class: class}
+
+	angle := o.Rotation * math.Pi / 180
+	sin, cos := float32(math.Sin(float64(angle))), float32(math.Cos(float64(angle)))
+	// points are relative to the object's origin and rotate around it
+	toWorld := func(x, y float32) phygo.Vector {
+		return phygo.NewVector(offsetX+o.X+x*cos-y*sin, offsetY+o.Y+x*sin+y*cos)
+	}
+
+	var err error
+	switch {
+	case o.Point:
+		return nil
+	case o.Polygon != nil || o.Polyline != nil:
+		points, closed := o.Polyline, false
+		if o.Polygon != nil {
+			points, closed = o.Polygon, true
+		}
+		outline := make([]phygo.Vector, len(points))
+		for i, p := range points {
+			outline[i] = toWorld(p.X, p.Y)
+		}
+		obj.Bodies, err = phygo.CreateChain(outline, l.opts.EdgeThickness, closed)
+	case o.Width <= 0 || o.Height <= 0:
+		// nothing to collide with
+		return nil
+	case o.Ellipse:
+		center := toWorld(o.Width/2, o.Height/2)
+		var b *phygo.Body
+		if b, err = phygo.CreateBodyCircle(center, (o.Width+o.Height)/4, 1, true); err == nil {
+			obj.Bodies = append(obj.Bodies, b)
+		}
+	default:
+		// tile objects have their origin at the bottom left
+		y := o.Height / 2
+		if o.Gid != 0 {
+			y = -o.Height / 2
+		}
+		var b *phygo.Body
+		if b, err = phygo.CreateBodyRectangle(toWorld(o.Width/2, y), o.Width, o.Height, 1, true); err == nil {
+			b.RotateTo(angle)
+			obj.Bodies = append(obj.Bodies, b)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, b := range obj.Bodies {
+		if err := l.apply(b, props); err != nil {
+			for _, b := range obj.Bodies {
+				phygo.RemoveBody(b)
+			}
+			return err
+		}
+	}
+	l.m.Bodies = append(l.m.Bodies, obj.Bodies...)
+	l.m.Objects = append(l.m.Objects, obj)
+	return nil
+}
+
+// Applies the body type and material properties
+func (l *loader) apply(b *phygo.Body, props properties) error {
+	bodyType := l.opts.BodyType
+	if raw, ok := props["bodyType"]; ok {
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return fmt.Errorf("property \"bodyType\" must be a string, got %s", raw)
+		}
+		switch name {
+		case "static":
+			bodyType = phygo.StaticBody
+		case "kinematic":
+			bodyType = phygo.KinematicBody
+		default:
+			return fmt.Errorf("property \"bodyType\" must be \"static\" or \"kinematic\", got %s", raw)
+		}
+	}
+	if bodyType == phygo.DynamicBody {
+		return fmt.Errorf("map bodies can't be dynamic")
+	}
+	b.SetBodyType(bodyType)
+
+	if v, ok, err := props.float("friction"); err != nil {
+		return err
+	} else if ok {
+		b.SetStaticFriction(v)
+		b.SetDynamicFriction(v)
+	}
+	setters := []struct {
+		name string
+		set  func(float32)
+	}{
+		{"staticFriction", b.SetStaticFriction},
+		{"dynamicFriction", b.SetDynamicFriction},
+		{"restitution", b.SetRestitution},
+	}
+	for _, s := range setters {
+		if v, ok, err := props.float(s.name); err != nil {
+			return err
+		} else if ok {
+			s.set(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package tiled
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	phygo "github.com/ab-dek/Phygo-2D"
+)
+
+type rect struct{ x, y, w, h float32 }
+
+func bodyRects(bodies []*phygo.Body) []rect {
+	var rects []rect
+	for _, b := range bodies {
+		pos := b.GetPos()
+		rects = append(rects, rect{pos.X, pos.Y, b.GetWidth(), b.GetHeight()})
+	}
+	return rects
+}
+
+// every fixture holds the same solid tiles, one of them flipped:
+//
+//	1 1 0 0
+//	1 1 0 1
+//	0 0 0 1
+func TestLoadEncodings(t *testing.T) {
+	want := []rect{{16, 16, 32, 32}, {56, 32, 16, 32}}
+	for _, name := range []string{"csv", "base64", "zlib", "gzip"} {
+		t.Run(name, func(t *testing.T) {
+			phygo.Close()
+			t.Cleanup(phygo.Close)
+			m, err := LoadFile(filepath.Join("testdata", name+".tmj"), Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bodyRects(m.Bodies); !reflect.DeepEqual(got, want) {
+				t.Errorf("bodies %v, expected %v", got, want)
+			}
+		})
+	}
+}
+
+// chunks are placed at their own tile coordinates, negative ones included
+func TestLoadInfinite(t *testing.T) {
+	phygo.Close()
+	t.Cleanup(phygo.Close)
+	m, err := LoadFile(filepath.Join("testdata", "infinite.tmj"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rect{{-16, 16, 32, 32}, {24, 24, 16, 16}}
+	if got := bodyRects(m.Bodies); !reflect.DeepEqual(got, want) {
+		t.Errorf("bodies %v, expected %v", got, want)
+	}
+}
+
+func TestDecodeDataErrors(t *testing.T) {
+	tests := []struct {
+		name                  string
+		data                  string
+		encoding, compression string
+	}{
+		{"unknown encoding", `"AAAA"`, "xml", ""},
+		{"unknown compression", `"AAAAAA=="`, "base64", "zstd"},
+		{"bad base64", `"not base64!"`, "base64", ""},
+		{"truncated gid", `"AAAA"`, "base64", ""},
+		{"bad zlib", `"AAAAAA=="`, "base64", "zlib"},
+		{"bad csv", `"1,2"`, "csv", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if gids, err := decodeData(json.RawMessage(tt.data), tt.encoding, tt.compression); err == nil {
+				t.Errorf("decoded %v, expected an error", gids)
+			}
+		})
+	}
+}
+
+func TestMergeTiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		grid   string
+		merged [][4]int
+	}{
+		{"empty", 3, "... ...", nil},
+		{"full", 3, "### ###", [][4]int{{0, 0, 3, 2}}},
+		{"row runs", 4, "##.# ....", [][4]int{{0, 0, 2, 1}, {3, 0, 1, 1}}},
+		{"column", 2, ".# .# .#", [][4]int{{1, 0, 1, 3}}},
+		// the first run can't grow into the shorter row below
+		{"L shape", 3, "### #..", [][4]int{{0, 0, 3, 1}, {0, 1, 1, 1}}},
+		{"step", 3, "#.. ###", [][4]int{{0, 0, 1, 2}, {1, 1, 2, 1}}},
+		{"checkerboard", 2, "#. .#", [][4]int{{0, 0, 1, 1}, {1, 1, 1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grid []bool
+			for _, c := range tt.grid {
+				if c != ' ' {
+					grid = append(grid, c == '#')
+				}
+			}
+			var merged [][4]int
+			err := mergeTiles(grid, tt.width, len(grid)/tt.width, func(x, y, w, h int) error {
+				merged = append(merged, [4]int{x, y, w, h})
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(merged, tt.merged) {
+				t.Errorf("merged into %v, expected %v", merged, tt.merged)
+			}
+		})
+	}
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func nearPos(b *phygo.Body, x, y float32) bool {
+	pos := b.GetPos()
+	return near(pos.X, x) && near(pos.Y, y)
+}
+
+func TestLoadObjects(t *testing.T) {
+	phygo.Close()
+	t.Cleanup(phygo.Close)
+	m, err := LoadFile(filepath.Join("testdata", "objects.tmj"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := map[string]Object{}
+	for _, o := range m.Objects {
+		objects[o.Name] = o
+	}
+	if len(m.Objects) != 6 || len(m.Bodies) != 9 {
+		t.Fatalf("%d objects with %d bodies, expected 6 with 9 (the point skipped)", len(m.Objects), len(m.Bodies))
+	}
+
+	floor := objects["floor"].Bodies[0]
+	if !nearPos(floor, 30, 30) || floor.GetWidth() != 40 || floor.GetHeight() != 20 {
+		t.Errorf("rectangle at %v, %vx%v, expected at (30, 30), 40x20", floor.GetPos(), floor.GetWidth(), floor.GetHeight())
+	}
+
+	ball := objects["ball"].Bodies[0]
+	if ball.ShapeType != phygo.CircleShape || !nearPos(ball, 110, 10) || ball.GetRadius() != 10 {
+		t.Errorf("ellipse at %v with radius %v, expected a circle at (110, 10) with radius 10", ball.GetPos(), ball.GetRadius())
+	}
+
+	// polygons are closed, polylines aren't
+	edges := []struct {
+		name    string
+		centers [][2]float32
+	}{
+		{"triangle", [][2]float32{{220, 0}, {240, 15}, {220, 15}}},
+		{"ledge", [][2]float32{{325, 0}, {350, 25}}},
+	}
+	for _, e := range edges {
+		bodies := objects[e.name].Bodies
+		if len(bodies) != len(e.centers) {
+			t.Errorf("%s has %d edges, expected %d", e.name, len(bodies), len(e.centers))
+			continue
+		}
+		for i, c := range e.centers {
+			if !nearPos(bodies[i], c[0], c[1]) || bodies[i].GetHeight() != 2 {
+				t.Errorf("%s edge %d at %v, expected at %v", e.name, i, bodies[i].GetPos(), c)
+			}
+		}
+	}
+
+	// rotated around its top left corner
+	door := objects["door"]
+	if b := door.Bodies[0]; !nearPos(b, -5, 110) || !near(b.Rotation, math.Pi/2) {
+		t.Errorf("rotated rectangle at %v rotated by %v, expected at (-5, 110) by pi/2", b.GetPos(), b.Rotation)
+	}
+	if door.Class != "door" {
+		t.Errorf("class %q, expected door", door.Class)
+	}
+
+	// tile objects have their origin at the bottom left corner
+	if crate := objects["crate"].Bodies[0]; !nearPos(crate, 108, 192) {
+		t.Errorf("tile object at %v, expected at (108, 192)", crate.GetPos())
+	}
+}
+
+// the innermost property wins: map, then layer, then object
+func TestLoadProperties(t *testing.T) {
+	phygo.Close()
+	t.Cleanup(phygo.Close)
+	m, err := LoadFile(filepath.Join("testdata", "objects.tmj"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name                         string
+		restitution, static, dynamic float32
+		bodyType                     phygo.BodyType
+	}{
+		{"floor", 0.9, 0.3, 0.3, phygo.StaticBody},
+		{"ball", 0.5, 0.3, 0.3, phygo.StaticBody},
+		{"door", 0.5, 0.8, 0.3, phygo.KinematicBody},
+	}
+	for _, tt := range tests {
+		for _, o := range m.Objects {
+			if o.Name != tt.name {
+				continue
+			}
+			b := o.Bodies[0]
+			if !near(b.GetRestitution(), tt.restitution) || !near(b.GetStaticFriction(), tt.static) ||
+				!near(b.GetDynamicFriction(), tt.dynamic) || b.GetBodyType() != tt.bodyType {
+				t.Errorf("%s has restitution %v, friction %v/%v and type %v, expected %v, %v/%v and %v", tt.name,
+					b.GetRestitution(), b.GetStaticFriction(), b.GetDynamicFriction(), b.GetBodyType(),
+					tt.restitution, tt.static, tt.dynamic, tt.bodyType)
+			}
+		}
+	}
+}
+
+func TestLoadBodyTypeErrors(t *testing.T) {
+	for _, value := range []string{`"dynamic"`, `"Static"`, `1`, `true`} {
+		t.Run(value, func(t *testing.T) {
+			phygo.Close()
+			t.Cleanup(phygo.Close)
+			data := `{"layers": [{"name": "objects", "type": "objectgroup", "objects": [
+				{"id": 1, "x": 0, "y": 0, "width": 10, "height": 10},
+				{"id": 2, "x": 0, "y": 0, "width": 10, "height": 10,
+					"properties": [{"name": "bodyType", "value": ` + value + `}]}
+			]}]}`
+			if _, err := Load(strings.NewReader(data), Options{}); err == nil || !strings.Contains(err.Error(), "bodyType") {
+				t.Errorf("got %v, expected a bodyType error", err)
+			}
+			if n := phygo.GetBodiesCount(); n != 0 {
+				t.Errorf("%d bodies left in the world, expected the map removed", n)
+			}
+		})
+	}
+}
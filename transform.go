@@ -10,6 +10,10 @@ type transform struct {
 }
 
 func NewTransform(x, y, angle float32) transform {
+	if deterministic {
+		sin, cos := sinCos(angle)
+		return transform{x, y, sin, cos}
+	}
 	return transform{
 		PosX: x,
 		PosY: y,
@@ -24,6 +28,6 @@ func TransformZero() transform {
 
 func VectorTransform(v Vector, t transform) Vector {
 	// applying rotation and translation transformations
-	return NewVector(t.Cos*v.X-t.Sin*v.Y+t.PosX,
-		t.Sin*v.X+t.Cos*v.Y+t.PosY)
+	return NewVector(float32(t.Cos*v.X)-float32(t.Sin*v.Y)+t.PosX,
+		float32(t.Sin*v.X)+float32(t.Cos*v.Y)+t.PosY)
 }
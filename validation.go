@@ -0,0 +1,114 @@
+package phygo
+
+import "fmt"
+
+type ValidationIssueKind int
+
+const (
+	// NaN or Inf in the body's state
+	InvalidValue ValidationIssueKind = iota
+	ExtremeVelocity
+	DeepPenetration
+	// a sub-step found more contacts than the world can hold and ignored some
+	ContactOverflow
+)
+
+// ValidationIssue is a problem found by the validation mode
+type ValidationIssue struct {
+	Kind ValidationIssueKind
+	// nil for a ContactOverflow
+	Body *Body
+	// the other body of a penetration, nil otherwise
+	Other *Body
+	// sub-step of the UpdatePhysics call where the issue was first found
+	Step    int
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	return i.Message
+}
+
+type ValidationOptions struct {
+	// max linear speed in Velocity units, 0 disables the check
+	MaxVelocity float32
+	// max contact depth in pixels, 0 disables the check
+	MaxPenetration float32
+	// called after an UpdatePhysics call that found issues
+	OnIssues func([]ValidationIssue)
+}
+
+var (
+	validation       *ValidationOptions
+	validationIssues []ValidationIssue
+)
+
+// Enables the debug validation mode, checking every body and contact after each
+// sub-step. Each issue is reported once per UpdatePhysics call. nil disables it.
+func SetValidation(opts *ValidationOptions) {
+	if opts == nil {
+		validation = nil
+		return
+	}
+	v := *opts
+	validation = &v
+}
+
+// Returns the issues found during the last UpdatePhysics call
+func GetValidationIssues() []ValidationIssue {
+	return validationIssues
+}
+
+func reportIssue(kind ValidationIssueKind, a, b *Body, step int, format string, args ...any) {
+	for _, issue := range validationIssues {
+		if issue.Kind == kind && issue.Body == a && issue.Other == b {
+			return
+		}
+	}
+	validationIssues = append(validationIssues, ValidationIssue{
+		Kind:    kind,
+		Body:    a,
+		Other:   b,
+		Step:    step,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func invalidVector(v Vector) bool {
+	return isInvalid(v.X) || isInvalid(v.Y)
+}
+
+func validateStep(step int) {
+	for _, b := range bodies[:bodyCount] {
+		switch {
+		case invalidVector(b.position):
+			reportIssue(InvalidValue, b, nil, step, "phygo: body %d has an invalid position %v", b.Id, b.GetPos())
+		case isInvalid(b.Rotation):
+			reportIssue(InvalidValue, b, nil, step, "phygo: body %d has an invalid rotation %v", b.Id, b.Rotation)
+		case invalidVector(b.Velocity) || isInvalid(b.AngularVelocity):
+			reportIssue(InvalidValue, b, nil, step, "phygo: body %d has an invalid velocity %v, %v", b.Id, b.Velocity, b.AngularVelocity)
+		case invalidVector(b.Force) || isInvalid(b.Torque):
+			reportIssue(InvalidValue, b, nil, step, "phygo: body %d has an invalid force %v, %v", b.Id, b.Force, b.Torque)
+		}
+
+		if validation.MaxVelocity > 0 {
+			if speed := VectorLen(b.Velocity); speed > validation.MaxVelocity {
+				reportIssue(ExtremeVelocity, b, nil, step, "phygo: body %d moves at %v, above %v", b.Id, speed, validation.MaxVelocity)
+			}
+		}
+	}
+
+	if droppedContacts > 0 {
+		reportIssue(ContactOverflow, nil, nil, step, "phygo: %d contacts ignored, a step holds at most %d", droppedContacts, maxManifold)
+	}
+
+	if validation.MaxPenetration > 0 {
+		for i := range manifolds[:manifoldCount] {
+			m := &manifolds[i]
+			if depth := m.Depth * ppu; depth > validation.MaxPenetration {
+				reportIssue(DeepPenetration, m.BodyA, m.BodyB, step, "phygo: bodies %d and %d overlap by %v pixels, above %v",
+					m.BodyA.Id, m.BodyB.Id, depth, validation.MaxPenetration)
+			}
+		}
+	}
+}
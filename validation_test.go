@@ -0,0 +1,87 @@
+package phygo
+
+import (
+	"math"
+	"testing"
+)
+
+// bodies piled on one spot make more contacts than a step can hold
+func TestContactOverflow(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	var issues []ValidationIssue
+	SetValidation(&ValidationOptions{OnIssues: func(i []ValidationIssue) {
+		issues = append(issues, i...)
+	}})
+	for i := 0; i < 200; i++ {
+		mustBody(t)(CreateBodyCircle(NewVector(300, 300), 10, 1, false))
+	}
+
+	UpdatePhysics(1.0 / 60)
+	if dropped := GetStats().DroppedContacts; dropped == 0 {
+		t.Error("no dropped contacts counted")
+	}
+	if len(issues) != 1 || issues[0].Kind != ContactOverflow {
+		t.Errorf("got issues %v, expected one contact overflow", issues)
+	}
+}
+
+// runs one update with the validation options and returns the issues reported
+func validateUpdate(opts ValidationOptions) []ValidationIssue {
+	var issues []ValidationIssue
+	opts.OnIssues = func(i []ValidationIssue) {
+		issues = append(issues, i...)
+	}
+	SetValidation(&opts)
+	UpdatePhysics(1.0 / 60)
+	return issues
+}
+
+func TestValidateInvalidValue(t *testing.T) {
+	resetWorld(t)
+	broken := mustBody(t)(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	broken.Velocity = NewVector(float32(math.NaN()), 0)
+
+	issues := validateUpdate(ValidationOptions{})
+	// reported once although every sub-step finds it
+	if len(issues) != 1 || issues[0].Kind != InvalidValue || issues[0].Body != broken || issues[0].Step != 0 {
+		t.Errorf("got issues %v, expected one invalid value of body %d", issues, broken.Id)
+	}
+}
+
+func TestValidateExtremeVelocity(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	must := mustBody(t)
+	fast := must(CreateBodyCircle(NewVector(100, 100), 10, 1, false))
+	fast.Velocity = NewVector(0.3, 0.4)
+	slow := must(CreateBodyCircle(NewVector(100, 300), 10, 1, false))
+	slow.Velocity = NewVector(0.1, 0)
+
+	issues := validateUpdate(ValidationOptions{MaxVelocity: 0.2})
+	if len(issues) != 1 || issues[0].Kind != ExtremeVelocity || issues[0].Body != fast {
+		t.Errorf("got issues %v, expected one extreme velocity of body %d", issues, fast.Id)
+	}
+	if issues := validateUpdate(ValidationOptions{}); len(issues) != 0 {
+		t.Errorf("got issues %v with the check disabled", issues)
+	}
+}
+
+func TestValidateDeepPenetration(t *testing.T) {
+	resetWorld(t)
+	SetGravity(0, 0)
+	SetIteration(1)
+	must := mustBody(t)
+	// 10 pixels deep
+	deepA := must(CreateBodyRectangle(NewVector(100, 100), 20, 20, 1, false))
+	deepB := must(CreateBodyRectangle(NewVector(110, 100), 20, 20, 1, false))
+	// 2 pixels deep
+	must(CreateBodyRectangle(NewVector(300, 100), 20, 20, 1, false))
+	must(CreateBodyRectangle(NewVector(318, 100), 20, 20, 1, false))
+
+	issues := validateUpdate(ValidationOptions{MaxPenetration: 5})
+	if len(issues) != 1 || issues[0].Kind != DeepPenetration ||
+		issues[0].Body != deepA || issues[0].Other != deepB {
+		t.Errorf("got issues %v, expected a deep penetration of bodies %d and %d", issues, deepA.Id, deepB.Id)
+	}
+}
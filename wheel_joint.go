@@ -0,0 +1,186 @@
+package phygo
+
+import "math"
+
+// WheelJoint keeps body B (the wheel) on a suspension axis fixed in body A,
+// with a spring along that axis and free rotation that can be driven by a motor.
+type WheelJoint struct {
+	jointBase
+	localAnchorA Vector
+	localAnchorB Vector
+	localAxisA   Vector
+
+	Frequency    float32 // suspension frequency in Hz, 0 makes the axis rigid
+	DampingRatio float32
+
+	EnableMotor    bool
+	MotorSpeed     float32 // radians per unit of time, same as AngularVelocity
+	MaxMotorTorque float32
+
+	rA, rB       Vector
+	ax, ay       Vector
+	sAx, sBx     float32
+	sAy, sBy     float32
+	mass         float32
+	bias         float32
+	springMass   float32
+	springBias   float32
+	gamma        float32
+	springImp    float32
+	motorMass    float32
+	motorImpulse float32
+}
+
+// anchor is the wheel center in pixels, axis is the world suspension direction
+func CreateWheelJoint(bodyA, bodyB *Body, anchor, axis Vector, frequency, dampingRatio float32) (*WheelJoint, error) {
+	if err := checkJointInput(bodyA, bodyB); err != nil {
+		return nil, err
+	}
+	anchor = VectorMul(anchor, 1/float32(ppu))
+
+	newJoint := &WheelJoint{
+		jointBase:    jointBase{bodyA: bodyA, bodyB: bodyB},
+		localAnchorA: localPoint(bodyA, anchor),
+		localAnchorB: localPoint(bodyB, anchor),
+		localAxisA:   rotateVector(VectorNormalize(axis), -bodyA.Rotation),
+		Frequency:    frequency,
+		DampingRatio: dampingRatio,
+	}
+	addJoint(newJoint)
+
+	return newJoint, nil
+}
+
+func (j *WheelJoint) GetType() JointType {
+	return WheelJointType
+}
+
+func (j *WheelJoint) GetAnchorA() Vector {
+	return worldAnchor(j.bodyA, j.localAnchorA)
+}
+
+func (j *WheelJoint) GetAnchorB() Vector {
+	return worldAnchor(j.bodyB, j.localAnchorB)
+}
+
+func (j *WheelJoint) copyInto(dst Joint) Joint {
+	d, ok := dst.(*WheelJoint)
+	if !ok {
+		d = &WheelJoint{}
+	}
+	*d = *j
+	return d
+}
+
+func (j *WheelJoint) hashState(h uint64) uint64 {
+	h = hashFloat(h, j.Frequency)
+	h = hashFloat(h, j.DampingRatio)
+	h = hashBool(h, j.EnableMotor)
+	h = hashFloat(h, j.MotorSpeed)
+	h = hashFloat(h, j.MaxMotorTorque)
+	h = hashFloat(h, j.springImp)
+	return hashFloat(h, j.motorImpulse)
+}
+
+// Returns the suspension travel along the axis in pixels
+func (j *WheelJoint) GetJointTranslation() float32 {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	pA := VectorAdd(bodyA.position, rotateVector(j.localAnchorA, bodyA.Rotation))
+	pB := VectorAdd(bodyB.position, rotateVector(j.localAnchorB, bodyB.Rotation))
+	return VectorDotProduct(VectorSubtract(pB, pA), rotateVector(j.localAxisA, bodyA.Rotation)) * ppu
+}
+
+func (j *WheelJoint) prepare(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+	h := dt * ppu
+
+	j.rA = rotateVector(j.localAnchorA, bodyA.Rotation)
+	j.rB = rotateVector(j.localAnchorB, bodyB.Rotation)
+	d := VectorSubtract(VectorAdd(bodyB.position, j.rB), VectorAdd(bodyA.position, j.rA))
+
+	mA, mB := bodyA.invMass, bodyB.invMass
+	iA, iB := bodyA.getInvInertia(), bodyB.getInvInertia()
+
+	// point to line constraint
+	j.ax = rotateVector(j.localAxisA, bodyA.Rotation)
+	j.ay = crossSV(1, j.ax)
+	j.sAy = VectorCrossProduct(VectorAdd(d, j.rA), j.ay)
+	j.sBy = VectorCrossProduct(j.rB, j.ay)
+	j.mass = mA + mB + float32(iA*j.sAy*j.sAy) + float32(iB*j.sBy*j.sBy)
+	if j.mass > 0 {
+		j.mass = 1 / j.mass
+	}
+	j.bias = VectorDotProduct(j.ay, d) * jointBaumgarte / h
+
+	// suspension spring
+	j.sAx = VectorCrossProduct(VectorAdd(d, j.rA), j.ax)
+	j.sBx = VectorCrossProduct(j.rB, j.ax)
+	j.springMass = 0
+	j.springBias = 0
+	j.gamma = 0
+	invMass := mA + mB + float32(iA*j.sAx*j.sAx) + float32(iB*j.sBx*j.sBx)
+	if j.Frequency <= 0 && invMass > 0 {
+		// no spring, the axis is held like the point to line constraint
+		j.springMass = 1 / invMass
+		j.springBias = VectorDotProduct(d, j.ax) * jointBaumgarte / h
+	} else if invMass > 0 {
+		mass := 1 / invMass
+		omega := 2 * math.Pi * j.Frequency / ppu
+		damping := 2 * mass * j.DampingRatio * omega
+		k := mass * omega * omega
+
+		j.gamma = h * (damping + float32(h*k))
+		if j.gamma > 0 {
+			j.gamma = 1 / j.gamma
+		}
+		j.springBias = VectorDotProduct(d, j.ax) * h * k * j.gamma
+
+		j.springMass = invMass + j.gamma
+		if j.springMass > 0 {
+			j.springMass = 1 / j.springMass
+		}
+	}
+	j.springImp = 0
+
+	// rotational motor
+	j.motorMass = iA + iB
+	if j.motorMass > 0 {
+		j.motorMass = 1 / j.motorMass
+	}
+	j.motorImpulse = 0
+}
+
+func (j *WheelJoint) solve(dt float32) {
+	bodyA, bodyB := j.bodyA, j.bodyB
+
+	// suspension spring
+	if j.springMass > 0 {
+		cdot := VectorDotProduct(j.ax, VectorSubtract(bodyB.Velocity, bodyA.Velocity)) + float32(j.sBx*bodyB.AngularVelocity) - float32(j.sAx*bodyA.AngularVelocity)
+		impulse := -j.springMass * (cdot + j.springBias + float32(j.gamma*j.springImp))
+		j.springImp += impulse
+
+		p := VectorMul(j.ax, impulse)
+		applyJointImpulse(bodyA, VectorMul(p, -1), -impulse*j.sAx)
+		applyJointImpulse(bodyB, p, impulse*j.sBx)
+	}
+
+	if j.EnableMotor {
+		cdot := bodyB.AngularVelocity - bodyA.AngularVelocity - j.MotorSpeed
+		impulse := -j.motorMass * cdot
+		oldImpulse := j.motorImpulse
+		maxImpulse := j.MaxMotorTorque * dt
+		j.motorImpulse = ClampFloat(j.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse = j.motorImpulse - oldImpulse
+
+		applyJointImpulse(bodyA, VectorZero(), -impulse)
+		applyJointImpulse(bodyB, VectorZero(), impulse)
+	}
+
+	// point to line constraint
+	cdot := VectorDotProduct(j.ay, VectorSubtract(bodyB.Velocity, bodyA.Velocity)) + float32(j.sBy*bodyB.AngularVelocity) - float32(j.sAy*bodyA.AngularVelocity)
+	impulse := -j.mass * (cdot + j.bias)
+
+	p := VectorMul(j.ay, impulse)
+	applyJointImpulse(bodyA, VectorMul(p, -1), -impulse*j.sAy)
+	applyJointImpulse(bodyB, p, impulse*j.sBy)
+}
@@ -0,0 +1,76 @@
+package phygo
+
+import "testing"
+
+// Puts the world back to its defaults between tests
+func resetWorld(t testing.TB) {
+	Close()
+	SetGravity(0, 1)
+	SetIteration(32)
+	SetFixedTimestep(0, defaultMaxStepsPerFrame)
+	SetDeterministic(false)
+	SetWorkers(1)
+	SetSleepEnabled(true)
+	SetSleepThresholds(0.005, 0.002, 0.5)
+	SetSleepCallbacks(nil, nil)
+	SetStatsCallback(nil)
+	SetValidation(nil)
+	StopRecording()
+	t.Cleanup(Close)
+}
+
+func mustBody(t testing.TB) func(b *Body, err error) *Body {
+	return func(b *Body, err error) *Body {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+}
+
+func noError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// a box pyramid on the ground, a pendulum and a thrown ball
+func setupMixedScene(t testing.TB) {
+	must := mustBody(t)
+	must(CreateBodyRectangle(NewVector(400, 500), 800, 40, 1, true))
+	for row := 0; row < 4; row++ {
+		for i := 0; i < 4-row; i++ {
+			x := 400 + (float32(i)-float32(4-row-1)/2)*42
+			must(CreateBodyRectangle(NewVector(x, 459-float32(row)*41), 40, 40, 1, false))
+		}
+	}
+
+	anchor := must(CreateBodyCircle(NewVector(400, 100), 5, 1, true))
+	bob := must(CreateBodyCircle(NewVector(550, 100), 15, 1, false))
+	_, err := CreateRevoluteJoint(anchor, bob, NewVector(400, 100))
+	noError(t, err)
+
+	ball := must(CreateBodyCircle(NewVector(150, 100), 10, 1, false))
+	ball.Velocity = NewVector(3, 0)
+}
+
+// steps the world and returns the state hash after each step
+func runHashes(steps int) []uint64 {
+	hashes := make([]uint64, steps)
+	for i := range hashes {
+		UpdatePhysics(1.0 / 60)
+		hashes[i] = StateHash()
+	}
+	return hashes
+}
+
+func compareHashes(t testing.TB, want, got []uint64) {
+	t.Helper()
+	for i := range want {
+		if want[i] != got[i] {
+			t.Fatalf("step %d: hash %x, expected %x", i, got[i], want[i])
+		}
+	}
+}